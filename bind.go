@@ -0,0 +1,249 @@
+package gokit
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const maxMultipartMemory = 32 << 20
+
+var (
+	ErrBindTarget = errors.New("gokit: bind target must be a non-nil pointer to a struct")
+
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// BindingError reports the fields that could not be bound, keyed by the
+// name used in the request. It renders as a 400.
+type BindingError struct {
+	Fields ValidationErrors
+}
+
+func (e *BindingError) Error() string {
+	return "binding failed: " + e.Fields.describe()
+}
+
+// Bind decodes the body according to its Content-Type (JSON, XML, urlencoded
+// or multipart form), then fills fields tagged query, path and header.
+func (c *Ctx) Bind(dst any) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return ErrBindTarget
+	}
+
+	errs := make(ValidationErrors)
+	if err := c.bindBody(dst, target.Elem(), errs); err != nil {
+		return err
+	}
+
+	c.bindTagged(target.Elem(), "query", func(key string) []string {
+		return c.request.URL.Query()[key]
+	}, errs)
+	c.bindTagged(target.Elem(), "path", func(key string) []string {
+		if value := c.request.PathValue(key); value != "" {
+			return []string{value}
+		}
+		return nil
+	}, errs)
+	c.bindTagged(target.Elem(), "header", func(key string) []string {
+		return c.request.Header.Values(key)
+	}, errs)
+
+	if len(errs) > 0 {
+		return &BindingError{Fields: errs}
+	}
+	return nil
+}
+
+func (c *Ctx) bindBody(dst any, target reflect.Value, errs ValidationErrors) error {
+	if c.request.Body == nil || c.request.Body == http.NoBody || c.request.ContentLength == 0 && c.request.Header.Get("Content-Type") == "" {
+		return nil
+	}
+
+	contentType, _, err := mime.ParseMediaType(c.request.Header.Get("Content-Type"))
+	if err != nil {
+		contentType = ""
+	}
+
+	switch {
+	case contentType == "application/json" || strings.HasSuffix(contentType, "+json"):
+		if err := json.NewDecoder(c.request.Body).Decode(dst); err != nil && err != io.EOF {
+			bodyError(err, errs)
+		}
+	case contentType == "application/xml" || contentType == "text/xml" || strings.HasSuffix(contentType, "+xml"):
+		if err := xml.NewDecoder(c.request.Body).Decode(dst); err != nil && err != io.EOF {
+			bodyError(err, errs)
+		}
+	case contentType == "application/x-www-form-urlencoded":
+		if err := c.request.ParseForm(); err != nil {
+			errs.Add("body", err.Error())
+			return nil
+		}
+		c.bindForm(target, c.request.PostForm, nil, errs)
+	case contentType == "multipart/form-data":
+		if err := c.request.ParseMultipartForm(maxMultipartMemory); err != nil {
+			errs.Add("body", err.Error())
+			return nil
+		}
+		c.bindForm(target, c.request.MultipartForm.Value, c.request.MultipartForm.File, errs)
+	default:
+		return NewHTTPError(http.StatusUnsupportedMediaType, "unsupported_media_type",
+			fmt.Sprintf("Content type %q is not supported.", contentType))
+	}
+	return nil
+}
+
+func bodyError(err error, errs ValidationErrors) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		errs.Add(typeErr.Field, fmt.Sprintf("must be of type %s", typeErr.Type))
+		return
+	}
+	errs.Add("body", err.Error())
+}
+
+func (c *Ctx) bindForm(target reflect.Value, values map[string][]string, files map[string][]*multipart.FileHeader, errs ValidationErrors) {
+	walkFields(target, func(field reflect.Value, info reflect.StructField) {
+		name := formName(info)
+		if name == "" {
+			return
+		}
+
+		switch field.Type() {
+		case fileHeaderType:
+			if headers := files[name]; len(headers) > 0 {
+				field.Set(reflect.ValueOf(headers[0]))
+			}
+			return
+		case fileHeadersType:
+			if headers := files[name]; len(headers) > 0 {
+				field.Set(reflect.ValueOf(headers))
+			}
+			return
+		}
+
+		if raw, ok := values[name]; ok {
+			if err := setFieldValues(field, raw); err != nil {
+				errs.Add(name, err.Error())
+			}
+		}
+	})
+}
+
+// formName uses the form tag, then the json tag, then the field name.
+func formName(info reflect.StructField) string {
+	for _, tag := range []string{"form", "json"} {
+		if name := strings.Split(info.Tag.Get(tag), ",")[0]; name != "" {
+			if name == "-" {
+				return ""
+			}
+			return name
+		}
+	}
+	return info.Name
+}
+
+func (c *Ctx) bindTagged(target reflect.Value, tag string, lookup func(string) []string, errs ValidationErrors) {
+	walkFields(target, func(field reflect.Value, info reflect.StructField) {
+		name := strings.Split(info.Tag.Get(tag), ",")[0]
+		if name == "" || name == "-" {
+			return
+		}
+		raw := lookup(name)
+		if len(raw) == 0 {
+			return
+		}
+		if err := setFieldValues(field, raw); err != nil {
+			errs.Add(name, err.Error())
+		}
+	})
+}
+
+// walkFields visits the exported fields of target, descending into embedded
+// structs.
+func walkFields(target reflect.Value, visit func(reflect.Value, reflect.StructField)) {
+	for i := 0; i < target.NumField(); i++ {
+		info := target.Type().Field(i)
+		if !info.IsExported() {
+			continue
+		}
+		field := target.Field(i)
+		if info.Anonymous && field.Kind() == reflect.Struct {
+			walkFields(field, visit)
+			continue
+		}
+		visit(field, info)
+	}
+}
+
+// setFieldValues converts raw request values into field. Slices take every
+// value, splitting comma-separated ones; other kinds take the first.
+func setFieldValues(field reflect.Value, raw []string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		items := make([]string, 0, len(raw))
+		for _, value := range raw {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setFieldValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setFieldValue(field, raw[0])
+}
+
+func setFieldValue(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setFieldValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	switch {
+	case field.Kind() == reflect.Bool:
+		switch strings.ToLower(value) {
+		case "1", "true", "yes", "on":
+			field.SetBool(true)
+			return nil
+		case "0", "false", "no", "off", "":
+			field.SetBool(false)
+			return nil
+		}
+		return fmt.Errorf("must be a boolean")
+	case field.Type() == timeType:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04", time.DateTime, time.DateOnly} {
+			if parsed, err := time.Parse(layout, value); err == nil {
+				field.Set(reflect.ValueOf(parsed))
+				return nil
+			}
+		}
+		return fmt.Errorf("must be a valid time")
+	}
+
+	if err := assignValue(field, value); err != nil {
+		return fmt.Errorf("must be of type %s", field.Type())
+	}
+	return nil
+}
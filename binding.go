@@ -0,0 +1,100 @@
+package gokit
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrDatabaseNotSet = errors.New("gokit: route model binding requires a database")
+
+type binding struct {
+	model      reflect.Type
+	parent     string
+	foreignKey string
+}
+
+func (r *router) SetDatabase(db Database) {
+	r.root.db = db
+}
+
+// Bind resolves the {param} path parameter to a model loaded by its id, or by
+// the column named in the route ({user=slug}). A missing record renders 404.
+func (r *router) Bind(param string, model any) {
+	r.root.bindings[param] = binding{model: indirectType(reflect.TypeOf(model))}
+}
+
+// BindScoped binds param like Bind, but only to records whose foreignKey
+// matches the id of the model already bound to parent, so
+// /users/{user}/posts/{post} cannot reach another user's post.
+func (r *router) BindScoped(param string, model any, parent, foreignKey string) {
+	r.root.bindings[param] = binding{
+		model:      indirectType(reflect.TypeOf(model)),
+		parent:     parent,
+		foreignKey: foreignKey,
+	}
+}
+
+// resolveBindings loads the models for the route's bound parameters in path
+// order, so a scoped child can see its parent.
+func (route *Route) resolveBindings(ctx *Ctx) error {
+	root := route.group.root
+	for _, param := range route.params {
+		binding, exists := root.bindings[param]
+		if !exists {
+			continue
+		}
+		if root.db == nil {
+			return ErrDatabaseNotSet
+		}
+
+		column := "id"
+		if key, ok := route.keys[param]; ok {
+			column = key
+		}
+		quoted, err := quoteIdentifier(column)
+		if err != nil {
+			return err
+		}
+
+		model := reflect.New(binding.model).Interface()
+		query := root.db.Model(model).WithContext(ctx.Context()).
+			Where(quoted+" = ?", ctx.Param(param))
+
+		if binding.parent != "" {
+			parentKey, ok := recordValue(reflect.ValueOf(ctx.Model(binding.parent)), "id")
+			if !ok {
+				return fmt.Errorf("gokit: binding %q is scoped to %q, which is not bound", param, binding.parent)
+			}
+			foreignKey, err := quoteIdentifier(binding.foreignKey)
+			if err != nil {
+				return err
+			}
+			query = query.Where(foreignKey+" = ?", parentKey)
+		}
+
+		if err := query.First(model); err != nil {
+			return err
+		}
+		ctx.setModel(param, model)
+	}
+	return nil
+}
+
+func (c *Ctx) Model(name string) any {
+	return c.models[name]
+}
+
+func (c *Ctx) setModel(name string, model any) {
+	if c.models == nil {
+		c.models = make(map[string]any)
+	}
+	c.models[name] = model
+}
+
+// Bound returns the model bound to name, or nil when there is none or it is
+// not a *T.
+func Bound[T any](ctx Context, name string) *T {
+	model, _ := ctx.Model(name).(*T)
+	return model
+}
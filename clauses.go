@@ -0,0 +1,206 @@
+package gokit
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var (
+	ErrInvalidIdentifier    = errors.New("gokit: invalid identifier")
+	ErrInvalidSortDirection = errors.New("gokit: invalid sort direction")
+	ErrInvalidOperator      = errors.New("gokit: invalid comparison operator")
+	ErrAggregateNotSelected = errors.New("gokit: aggregated column is not selected by the grouped query")
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+var aliasPattern = regexp.MustCompile(`(?i)\sAS\s+"?([A-Za-z_][A-Za-z0-9_]*)"?\s*$`)
+
+var comparisonOperators = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, ">": true, "<=": true, ">=": true,
+}
+
+func quoteIdentifier(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if !identifierPattern.MatchString(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + part + `"`
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func sortDirection(direction ...string) (string, error) {
+	if len(direction) == 0 {
+		return "ASC", nil
+	}
+
+	dir := strings.ToUpper(strings.TrimSpace(direction[0]))
+	if dir != "ASC" && dir != "DESC" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSortDirection, direction[0])
+	}
+	return dir, nil
+}
+
+func comparisonOperator(operator string) (string, error) {
+	operator = strings.TrimSpace(operator)
+	if !comparisonOperators[operator] {
+		return "", fmt.Errorf("%w: %q", ErrInvalidOperator, operator)
+	}
+	return operator, nil
+}
+
+type whereClause struct {
+	boolean   string
+	condition sq.Sqlizer
+}
+
+// whereGroup joins its clauses with their own AND/OR connectors, wrapping
+// each condition in parentheses so nested groups keep their precedence.
+type whereGroup []whereClause
+
+func (w whereGroup) ToSql() (string, []any, error) {
+	var b strings.Builder
+	args := make([]any, 0)
+
+	for _, clause := range w {
+		query, clauseArgs, err := clause.condition.ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+		if query == "" {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString(" " + clause.boolean + " ")
+		}
+		b.WriteString("(" + query + ")")
+		args = append(args, clauseArgs...)
+	}
+	return b.String(), args, nil
+}
+
+type notCondition struct {
+	condition sq.Sqlizer
+}
+
+func (n notCondition) ToSql() (string, []any, error) {
+	query, args, err := n.condition.ToSql()
+	if err != nil || query == "" {
+		return query, args, err
+	}
+	return "NOT (" + query + ")", args, nil
+}
+
+type subqueryBuilder interface {
+	subquery() (sq.Sqlizer, error)
+}
+
+func toSubquery(builder QueryBuilder) (sq.Sqlizer, error) {
+	sub, ok := builder.(subqueryBuilder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedArgument, builder)
+	}
+	return sub.subquery()
+}
+
+type commonTable struct {
+	name      string
+	recursive bool
+	query     sq.Sqlizer
+}
+
+type union struct {
+	all   bool
+	query sq.Sqlizer
+}
+
+func withCommonTables(ctes []commonTable, query sq.Sqlizer) sq.Sqlizer {
+	if len(ctes) == 0 {
+		return query
+	}
+
+	keyword := "WITH "
+	parts := make([]string, 0, len(ctes))
+	args := make([]any, 0, len(ctes)+1)
+	for _, cte := range ctes {
+		if cte.recursive {
+			keyword = "WITH RECURSIVE "
+		}
+		parts = append(parts, cte.name+" AS (?)")
+		args = append(args, cte.query)
+	}
+	return sq.Expr(keyword+strings.Join(parts, ", ")+" ?", append(args, query)...)
+}
+
+func withUnions(unions []union, query sq.Sqlizer) sq.Sqlizer {
+	if len(unions) == 0 {
+		return query
+	}
+
+	var b strings.Builder
+	b.WriteString("(?)")
+	args := []any{query}
+	for _, u := range unions {
+		if u.all {
+			b.WriteString(" UNION ALL (?)")
+		} else {
+			b.WriteString(" UNION (?)")
+		}
+		args = append(args, u.query)
+	}
+	return sq.Expr(b.String(), args...)
+}
+
+// outputName returns the name a select expression has in the result: its
+// alias, or the column without its table.
+func outputName(expression string) string {
+	expression = strings.TrimSpace(expression)
+	if match := aliasPattern.FindStringSubmatch(expression); match != nil {
+		return match[1]
+	}
+	if i := strings.LastIndex(expression, "."); i >= 0 {
+		expression = expression[i+1:]
+	}
+	return strings.Trim(expression, `"`)
+}
+
+func selectsColumn(columns []string, name string) bool {
+	for _, column := range columns {
+		if outputName(column) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func aggregateColumn(column string) (string, error) {
+	if column == "*" {
+		return column, nil
+	}
+	return quoteIdentifier(column)
+}
+
+// aggregatedColumn resolves column against the columns of a wrapped query,
+// returning how the outer query refers to it.
+func aggregatedColumn(columns []string, column string) (string, error) {
+	if column == "*" {
+		return column, nil
+	}
+	if _, err := quoteIdentifier(column); err != nil {
+		return "", err
+	}
+	name := outputName(column)
+	if len(columns) > 0 && !selectsColumn(columns, name) {
+		return "", fmt.Errorf("%w: %s", ErrAggregateNotSelected, column)
+	}
+	return quoteIdentifier(name)
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type config struct {
@@ -76,6 +77,21 @@ func (c *config) GetInt(key string) int {
 	return intVal
 }
 
+func (c *config) GetDuration(key string) time.Duration {
+	value := c.Get(key)
+	if value == "" {
+		return 0
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
 func (c *config) GetBool(key string) bool {
 	value := strings.ToLower(c.Get(key))
 	return value == "true" || value == "1" || value == "yes"
@@ -5,11 +5,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 )
 
 type Ctx struct {
-	request  *http.Request
-	response http.ResponseWriter
+	request      *http.Request
+	response     *responseWriter
+	errorHandler ErrorHandlerFunc
+	models       map[string]any
+	version      string
+	locals       map[string]any
+	encoders     *EncoderRegistry
+	validator    Validator
+	mu           sync.RWMutex
 }
 
 func (c *Ctx) Body() []byte {
@@ -20,23 +28,58 @@ func (c *Ctx) Body() []byte {
 	return body
 }
 
+func (c *Ctx) Created(location string, obj any) {
+	c.SetHeader("Location", location)
+	if obj == nil {
+		c.response.WriteHeader(http.StatusCreated)
+		return
+	}
+	c.JSON(http.StatusCreated, obj)
+}
+
+func (c *Ctx) Cookie(name string) (*http.Cookie, error) {
+	return c.request.Cookie(name)
+}
+
+// Data writes raw bytes, sniffing the content type unless one was set.
 func (c *Ctx) Data(code int, data []byte) {
+	if c.response.Header().Get("Content-Type") == "" {
+		c.response.Header().Set("Content-Type", http.DetectContentType(data))
+	}
 	c.response.WriteHeader(code)
-	c.response.Header().Add("Content-Type", "application/json")
 	c.response.Write(data)
 }
 
+// Error hands err to the error handler, discarding anything the handler
+// wrote that has not reached the client yet.
+func (c *Ctx) Error(err error) {
+	c.response.reset()
+	if c.errorHandler == nil {
+		DefaultErrorHandler(c, err)
+		return
+	}
+	c.errorHandler(c, err)
+}
+
 func (c *Ctx) Header(key string) string {
 	return c.request.Header.Get(key)
 }
 
 func (c *Ctx) JSON(code int, obj any) {
-	data, _ := json.Marshal(obj)
+	data, err := json.Marshal(obj)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.response.Header().Set("Content-Type", "application/json")
 	c.response.WriteHeader(code)
-	c.response.Header().Add("Content-Type", "application/json")
 	c.response.Write(data)
 }
 
+func (c *Ctx) NoContent() {
+	c.response.WriteHeader(http.StatusNoContent)
+}
+
 func (c *Ctx) Param(key string) string {
 	return c.request.PathValue(key)
 }
@@ -62,14 +105,38 @@ func (c *Ctx) Query(key string) string {
 	return c.request.URL.Query().Get(key)
 }
 
+func (c *Ctx) Redirect(code int, url string) {
+	http.Redirect(c.response, c.request, url, code)
+}
+
 func (c *Ctx) Request() *http.Request {
 	return c.request
 }
 
+func (c *Ctx) Response() Response {
+	return c.response
+}
+
+func (c *Ctx) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.response, cookie)
+}
+
+func (c *Ctx) SetHeader(key, value string) {
+	c.response.Header().Set(key, value)
+}
+
+func (c *Ctx) SetRequest(r *http.Request) {
+	c.request = r
+}
+
+func (c *Ctx) Status(code int) {
+	c.response.WriteHeader(code)
+}
+
 func (c *Ctx) String(code int, format string, values ...any) {
 	body := fmt.Sprintf(format, values...)
+	c.response.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	c.response.WriteHeader(code)
-	c.response.Header().Add("Content-Type", "text/plain; charset=utf-8")
 	c.response.Write([]byte(body))
 }
 
@@ -77,9 +144,10 @@ func (c *Ctx) Writer() http.ResponseWriter {
 	return c.response
 }
 
+// NewCtx wraps w without buffering, so writes reach it immediately.
 func NewCtx(w http.ResponseWriter, r *http.Request) Context {
 	return &Ctx{
 		request:  r,
-		response: w,
+		response: newResponseWriter(w, false),
 	}
 }
@@ -1,14 +1,18 @@
 package gokit
 
 import (
+	"context"
 	"database/sql"
+	"io"
 	"net/http"
+	"time"
 )
 
 const (
-	RouterBinding   = "gokit.router"
-	ConfigBinding   = "gokit.config"
-	DatabaseBinding = "gokit.database"
+	RouterBinding    = "gokit.router"
+	ConfigBinding    = "gokit.config"
+	DatabaseBinding  = "gokit.database"
+	ValidatorBinding = "gokit.validator"
 )
 
 type Config interface {
@@ -18,6 +22,7 @@ type Config interface {
 	All() map[string]string
 	GetInt(key string) int
 	GetBool(key string) bool
+	GetDuration(key string) time.Duration
 }
 
 type Application interface {
@@ -26,10 +31,12 @@ type Application interface {
 	Make(key string) any
 
 	AddProvider(provider ServiceProvider)
+	Shutdown()
 
 	Config() Config
 	Router() Router
 	DB() Database
+	Validator() Validator
 }
 
 type ServiceProvider interface {
@@ -37,28 +44,71 @@ type ServiceProvider interface {
 	Boot(app Application)
 }
 
-type Router interface {
-	GET(path string, handler HandlerFunc)
-	POST(path string, handler HandlerFunc)
-	PUT(path string, handler HandlerFunc)
-	DELETE(path string, handler HandlerFunc)
-	PATCH(path string, handler HandlerFunc)
+// ShutdownProvider is implemented by providers that release resources when
+// the application shuts down.
+type ShutdownProvider interface {
+	Shutdown(app Application)
+}
 
-	Group(prefix string, fn func(Router))
+type Router interface {
+	GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	HEAD(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	OPTIONS(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Any(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+	Match(methods []string, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route
+
+	Group(prefix string, fn func(Router), middleware ...MiddlewareFunc)
+	Domain(host string, fn func(Router), middleware ...MiddlewareFunc)
+	Version(version string, fn func(Router), middleware ...MiddlewareFunc)
+	Mount(prefix string, handler http.Handler, middleware ...MiddlewareFunc) *Route
+	SetVersioning(config VersionConfig)
+	DeprecateVersion(version string, deprecatedAt, sunset time.Time)
+	Resource(path string, controller any, middleware ...MiddlewareFunc)
+	APIResource(path string, controller any, middleware ...MiddlewareFunc)
 
 	Use(middleware ...MiddlewareFunc)
-
+	Routes() []*Route
+	Validate() error
+	Bind(param string, model any)
+	BindScoped(param string, model any, parent, foreignKey string)
+	SetDatabase(db Database)
+	SetValidator(validator Validator)
+	URL(name string, params ...any) (string, error)
+	SignedURL(name string, expiresAt time.Time, params ...any) (string, error)
+	SetSigningKey(key []byte)
+	SetErrorHandler(handler ErrorHandlerFunc)
+	RegisterEncoder(mediaType string, encoder Encoder)
+	NotFound(handler HandlerFunc)
+	MethodNotAllowed(handler HandlerFunc)
+
+	Handler() http.Handler
 	Listen(addr string) error
+	ListenTLS(addr, certFile, keyFile string) error
+	Shutdown(ctx context.Context) error
+	OnShutdown(fn func())
 }
 
 type HandlerFunc func(Context)
-type MiddlewareFunc func(Context)
+type MiddlewareFunc func(ctx Context, next Next)
+type Next func()
 
 type Context interface {
 	Param(key string) string
+	ParamInt(key string) (int, error)
+	ParamUUID(key string) (string, error)
 	Query(key string) string
+	QueryInt(key string, def int) (int, error)
+	QueryBool(key string, def bool) (bool, error)
+	QuerySlice(key string) []string
+	QueryTime(key string, def time.Time, layout ...string) (time.Time, error)
 	Header(key string) string
 
+	Bind(dst any) error
+	BindAndValidate(dst any) error
 	ParseJSON(v any) error
 	ParseString() string
 	Body() []byte
@@ -66,15 +116,51 @@ type Context interface {
 	JSON(code int, obj any)
 	String(code int, format string, values ...any)
 	Data(code int, data []byte)
+	Status(code int)
+	SetHeader(key, value string)
+	Cookie(name string) (*http.Cookie, error)
+	SetCookie(cookie *http.Cookie)
+	Redirect(code int, url string)
+	NoContent()
+	Created(location string, obj any)
+	Negotiate(code int, data any)
+	Render(code int, contentType string, encoder Encoder, data any)
+	XML(code int, obj any)
+	YAML(code int, obj any)
+	JSONP(code int, obj any)
+	HTML(code int, html string)
+	File(path string)
+	Download(path, name string)
+	Stream(code int, contentType string, reader io.Reader) error
+	Error(err error)
+
+	Model(name string) any
+	Version() string
+
+	Set(key string, value any)
+	Get(key string) (any, bool)
+	MustGet(key string) any
+	Context() context.Context
 
 	Request() *http.Request
+	SetRequest(r *http.Request)
 	Writer() http.ResponseWriter
+	Response() Response
+}
+
+type Validator interface {
+	Validate(ctx context.Context, target any) error
+	RegisterRule(name string, rule ValidationRule)
+	RegisterMessages(locale string, messages map[string]string)
+	SetDatabase(db Database)
 }
 
 type Response interface {
 	Status() int
 	Body() []byte
 	Headers() map[string]string
+	Size() int
+	Written() bool
 }
 
 type Database interface {
@@ -84,6 +170,9 @@ type Database interface {
 	Model(model any) QueryBuilder
 	Raw(sql string, args ...any) QueryBuilder
 
+	Relate(table, name string, relation Relation)
+	AddGlobalScope(target any, name string, scope GlobalScope)
+
 	Begin() (Transaction, error)
 	Transaction(fn func(tx Transaction) error) error
 
@@ -91,12 +180,23 @@ type Database interface {
 	Seed() error
 
 	Exec(sql string, args ...any) error
+	Close() error
 }
 
 type QueryBuilder interface {
+	WithContext(ctx context.Context) QueryBuilder
+	WithoutGlobalScope(names ...string) QueryBuilder
+
 	Select(columns ...string) QueryBuilder
 	Where(query any, args ...any) QueryBuilder
-	WhereIn(column string, values []any) QueryBuilder
+	OrWhere(query any, args ...any) QueryBuilder
+	WhereNot(query any, args ...any) QueryBuilder
+	WhereIn(column string, values any) QueryBuilder
+	WhereNull(column string) QueryBuilder
+	WhereNotNull(column string) QueryBuilder
+	WhereBetween(column string, from, to any) QueryBuilder
+	WhereLike(column string, pattern string) QueryBuilder
+	WhereColumn(first, operator, second string) QueryBuilder
 	Join(query string, args ...any) QueryBuilder
 	OrderBy(column string, direction ...string) QueryBuilder
 	GroupBy(columns ...string) QueryBuilder
@@ -104,8 +204,16 @@ type QueryBuilder interface {
 	Limit(limit int) QueryBuilder
 	Offset(offset int) QueryBuilder
 
+	FromSub(builder QueryBuilder, alias string) QueryBuilder
+	WithCTE(name string, builder QueryBuilder) QueryBuilder
+	WithRecursive(name string, builder QueryBuilder) QueryBuilder
+	Union(builder QueryBuilder) QueryBuilder
+	UnionAll(builder QueryBuilder) QueryBuilder
+
 	Preload(query string, args ...any) QueryBuilder
 	Joins(query string, args ...any) QueryBuilder
+	With(relations ...string) QueryBuilder
+	WhereHas(relation string, fn func(QueryBuilder)) QueryBuilder
 
 	Find(dest any) error
 	First(dest any) error
@@ -113,6 +221,14 @@ type QueryBuilder interface {
 	Update(values any) error
 	Delete() error
 	Count() (int64, error)
+	CountDistinct(column string) (int64, error)
+	Sum(column string) (float64, error)
+	Avg(column string) (float64, error)
+	Min(column string) (float64, error)
+	Max(column string) (float64, error)
+	Exists() (bool, error)
+	Pluck(column string, dest any) error
+	Value(column string, dest any) error
 
 	Begin() (QueryBuilder, error)
 	Commit() error
@@ -1,7 +1,9 @@
 package gokit
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	_ "github.com/lib/pq"
@@ -57,6 +59,15 @@ func (c *Connection) GORM() *gorm.DB {
 	return c.gormDB
 }
 
+func (c *Connection) Close() error {
+	var errs []error
+	if gormSQL, err := c.gormDB.DB(); err == nil {
+		errs = append(errs, gormSQL.Close())
+	}
+	errs = append(errs, c.sqlDB.Close())
+	return errors.Join(errs...)
+}
+
 func buildDSN(config Config, driver string) string {
 	switch driver {
 	case "postgres":
@@ -73,12 +84,18 @@ func buildDSN(config Config, driver string) string {
 }
 
 type DB struct {
-	conn *Connection
+	conn      *Connection
+	relations *RelationRegistry
+	scopes    *ScopeRegistry
 }
 
 func NewDB(config Config) Database {
 	conn := NewConnection(config)
-	return &DB{conn: conn}
+	return &DB{
+		conn:      conn,
+		relations: NewRelationRegistry(),
+		scopes:    NewScopeRegistry(),
+	}
 }
 
 func (d *DB) Connection() *sql.DB {
@@ -86,11 +103,11 @@ func (d *DB) Connection() *sql.DB {
 }
 
 func (d *DB) Table(name string) QueryBuilder {
-	return nil
+	return NewTableQueryBuilder(d.conn.SQL(), name, d.relations, d.scopes)
 }
 
 func (d *DB) Model(model any) QueryBuilder {
-	return NewGormQueryBuilder(d.conn.GORM(), model)
+	return NewGormQueryBuilder(d.conn.GORM(), model, d.relations, d.scopes)
 }
 
 func (d *DB) Raw(sql string, args ...any) QueryBuilder {
@@ -109,7 +126,7 @@ func (d *DB) Begin() (Transaction, error) {
 		return nil, gormTx.Error
 	}
 
-	return NewTransaction(sqlTx, gormTx), nil
+	return NewTransaction(sqlTx, gormTx, d.relations, d.scopes), nil
 }
 
 func (d *DB) Transaction(fn func(tx Transaction) error) error {
@@ -133,6 +150,22 @@ func (d *DB) Transaction(fn func(tx Transaction) error) error {
 	return tx.Commit()
 }
 
+func (d *DB) Relate(table, name string, relation Relation) {
+	d.relations.Define(table, name, relation)
+}
+
+func (d *DB) AddGlobalScope(target any, name string, scope GlobalScope) {
+	table, ok := target.(string)
+	if !ok {
+		stmt := &gorm.Statement{DB: d.conn.GORM()}
+		if err := stmt.Parse(target); err != nil {
+			panic(fmt.Sprintf("Failed to resolve table for global scope %s: %v", name, err))
+		}
+		table = stmt.Schema.Table
+	}
+	d.scopes.Add(table, name, scope)
+}
+
 func (d *DB) Migrate() error {
 	return nil
 }
@@ -146,24 +179,45 @@ func (d *DB) Exec(sql string, args ...any) error {
 	return err
 }
 
+func (d *DB) Close() error {
+	return d.conn.Close()
+}
+
+// ErrTxNoTable is returned by queries built on a transaction itself, which
+// has no table to read from.
+var ErrTxNoTable = errors.New("gokit: transaction has no table; query it through tx.Table(...) or tx.Model(...)")
+
 type Tx struct {
-	sqlTx  *sql.Tx
-	gormTx *gorm.DB
+	sqlTx     *sql.Tx
+	gormTx    *gorm.DB
+	relations *RelationRegistry
+	scopes    *ScopeRegistry
 }
 
-func NewTransaction(sqlTx *sql.Tx, gormTx *gorm.DB) Transaction {
+func NewTransaction(sqlTx *sql.Tx, gormTx *gorm.DB, relations *RelationRegistry, scopes *ScopeRegistry) Transaction {
 	return &Tx{
-		sqlTx:  sqlTx,
-		gormTx: gormTx,
+		sqlTx:     sqlTx,
+		gormTx:    gormTx,
+		relations: relations,
+		scopes:    scopes,
 	}
 }
 
 func (t *Tx) Table(name string) QueryBuilder {
-	return nil
+	return NewTableQueryBuilder(t.sqlTx, name, t.relations, t.scopes)
 }
 
 func (t *Tx) Model(model any) QueryBuilder {
-	return NewGormQueryBuilder(t.gormTx, model)
+	return NewGormQueryBuilder(t.gormTx, model, t.relations, t.scopes)
+}
+
+// noTable backs the query methods called on the transaction itself; the
+// builder carries ErrTxNoTable so the query fails before reaching the
+// database.
+func (t *Tx) noTable() QueryBuilder {
+	query := newTableQueryBuilder(t.sqlTx, "", t.relations, t.scopes)
+	query.err = ErrTxNoTable
+	return query
 }
 
 func (t *Tx) Commit() error {
@@ -187,71 +241,167 @@ func (t *Tx) RollbackTo(name string) error {
 }
 
 func (t *Tx) Select(columns ...string) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
 func (t *Tx) Where(query any, args ...any) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
-func (t *Tx) WhereIn(column string, values []any) QueryBuilder {
-	return t.Table("")
+func (t *Tx) OrWhere(query any, args ...any) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereNot(query any, args ...any) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereIn(column string, values any) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereNull(column string) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereNotNull(column string) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereBetween(column string, from, to any) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereLike(column string, pattern string) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereColumn(first, operator, second string) QueryBuilder {
+	return t.noTable()
 }
 
 func (t *Tx) Join(query string, args ...any) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
 func (t *Tx) OrderBy(column string, direction ...string) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
 func (t *Tx) GroupBy(columns ...string) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
 func (t *Tx) Having(query any, args ...any) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
 func (t *Tx) Limit(limit int) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
 func (t *Tx) Offset(offset int) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
+}
+
+func (t *Tx) FromSub(builder QueryBuilder, alias string) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WithCTE(name string, builder QueryBuilder) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WithRecursive(name string, builder QueryBuilder) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) Union(builder QueryBuilder) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) UnionAll(builder QueryBuilder) QueryBuilder {
+	return t.noTable()
 }
 
 func (t *Tx) Preload(query string, args ...any) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
 }
 
 func (t *Tx) Joins(query string, args ...any) QueryBuilder {
-	return t.Table("")
+	return t.noTable()
+}
+
+func (t *Tx) With(relations ...string) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WhereHas(relation string, fn func(QueryBuilder)) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WithContext(ctx context.Context) QueryBuilder {
+	return t.noTable()
+}
+
+func (t *Tx) WithoutGlobalScope(names ...string) QueryBuilder {
+	return t.noTable()
 }
 
 func (t *Tx) Find(dest any) error {
-	return nil
+	return ErrTxNoTable
 }
 
 func (t *Tx) First(dest any) error {
-	return nil
+	return ErrTxNoTable
 }
 
 func (t *Tx) Create(value any) error {
-	return nil
+	return ErrTxNoTable
 }
 
 func (t *Tx) Update(values any) error {
-	return nil
+	return ErrTxNoTable
 }
 
 func (t *Tx) Delete() error {
-	return nil
+	return ErrTxNoTable
 }
 
 func (t *Tx) Count() (int64, error) {
-	return 0, nil
+	return 0, ErrTxNoTable
+}
+
+func (t *Tx) CountDistinct(column string) (int64, error) {
+	return 0, ErrTxNoTable
+}
+
+func (t *Tx) Sum(column string) (float64, error) {
+	return 0, ErrTxNoTable
+}
+
+func (t *Tx) Avg(column string) (float64, error) {
+	return 0, ErrTxNoTable
+}
+
+func (t *Tx) Min(column string) (float64, error) {
+	return 0, ErrTxNoTable
+}
+
+func (t *Tx) Max(column string) (float64, error) {
+	return 0, ErrTxNoTable
+}
+
+func (t *Tx) Exists() (bool, error) {
+	return false, ErrTxNoTable
+}
+
+func (t *Tx) Pluck(column string, dest any) error {
+	return ErrTxNoTable
+}
+
+func (t *Tx) Value(column string, dest any) error {
+	return ErrTxNoTable
 }
 
 func (t *Tx) Begin() (QueryBuilder, error) {
@@ -259,5 +409,5 @@ func (t *Tx) Begin() (QueryBuilder, error) {
 }
 
 func (t *Tx) ToSQL() (string, []any, error) {
-	return "", nil, nil
+	return "", nil, ErrTxNoTable
 }
@@ -0,0 +1,139 @@
+package gokit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Domain groups routes that only match requests for host. Literal hosts use
+// ServeMux host patterns; hosts with {placeholders} are matched per request
+// and their values are readable through ctx.Param.
+func (r *router) Domain(host string, fn func(Router), middleware ...MiddlewareFunc) {
+	groupRouter := r.subrouter(r.prefix, strings.ToLower(host), r.version, middleware)
+	fn(groupRouter)
+	r.routes = append(r.routes, groupRouter.routes...)
+}
+
+func (route *Route) setHost(host string) error {
+	if host == "" {
+		return nil
+	}
+
+	pattern, err := parsePattern(host)
+	if err != nil {
+		return err
+	}
+	route.host = pattern.path
+	if len(pattern.params) == 0 {
+		return nil
+	}
+
+	expr := regexp.QuoteMeta(pattern.path)
+	for _, param := range pattern.params {
+		expr = strings.Replace(expr, regexp.QuoteMeta("{"+param+"}"), "([^.]+)", 1)
+	}
+	route.hostPattern = regexp.MustCompile("^" + expr + "$")
+	route.hostParams = pattern.params
+	route.hostConstraints = pattern.constraints
+	return nil
+}
+
+// muxPattern includes the host only when it is literal; wildcard hosts are
+// resolved by the dispatcher.
+func (route *Route) muxPattern() string {
+	method := route.method + " "
+	if route.method == mountMethod {
+		method = ""
+	}
+	if route.host != "" && route.hostPattern == nil {
+		return method + route.host + route.path
+	}
+	return method + route.path
+}
+
+// accepts reports whether the route applies to req, capturing host
+// parameters as path values.
+func (route *Route) accepts(req *http.Request) bool {
+	if !route.acceptsVersion(req) {
+		return false
+	}
+	if route.hostPattern == nil {
+		return true
+	}
+
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	match := route.hostPattern.FindStringSubmatch(strings.ToLower(host))
+	if match == nil {
+		return false
+	}
+	for i, param := range route.hostParams {
+		if matcher, ok := route.hostConstraints[param]; ok && !matcher.MatchString(match[i+1]) {
+			return false
+		}
+	}
+	for i, param := range route.hostParams {
+		req.SetPathValue(param, match[i+1])
+	}
+	return true
+}
+
+// dispatcher serves one mux pattern, trying wildcard-host and versioned routes
+// before the plain route.
+type dispatcher struct {
+	routes []*Route
+}
+
+func (d *dispatcher) add(route *Route) error {
+	for _, existing := range d.routes {
+		if existing.host == route.host && existing.version == route.version {
+			return fmt.Errorf("%w: %s is already registered", ErrRouteConflict, route.describe())
+		}
+	}
+
+	i := len(d.routes)
+	for i > 0 && d.routes[i-1].specificity() < route.specificity() {
+		i--
+	}
+	d.routes = slices.Insert(d.routes, i, route)
+	return nil
+}
+
+func (route *Route) specificity() int {
+	score := 0
+	if route.hostPattern != nil {
+		score += 2
+	}
+	if route.version != "" {
+		score++
+	}
+	return score
+}
+
+func (route *Route) describe() string {
+	description := route.method + " " + route.host + route.path
+	if route.version != "" {
+		description += " (version " + route.version + ")"
+	}
+	return description
+}
+
+func (d *dispatcher) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	for _, route := range d.routes {
+		if route.accepts(req) {
+			route.serve(w, req)
+			return
+		}
+	}
+
+	root := d.routes[0].group.root
+	root.run(w, req, "", root.middleware, func(ctx *Ctx) {
+		root.notFound(ctx)
+	})
+}
@@ -0,0 +1,256 @@
+package gokit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnsupportedCSV = errors.New("gokit: value cannot be encoded as CSV")
+
+type Encoder func(w io.Writer, data any) error
+
+type EncoderRegistry struct {
+	encoders map[string]Encoder
+	order    []string
+	mu       sync.RWMutex
+}
+
+func NewEncoderRegistry() *EncoderRegistry {
+	return &EncoderRegistry{
+		encoders: make(map[string]Encoder),
+		order:    make([]string, 0),
+	}
+}
+
+// DefaultEncoders returns a registry with the built-in formats. JSON comes
+// first, so it is chosen when the client accepts anything.
+func DefaultEncoders() *EncoderRegistry {
+	registry := NewEncoderRegistry()
+	registry.Register("application/json", encodeJSON)
+	registry.Register("application/xml", encodeXML)
+	registry.Register("application/yaml", encodeYAML)
+	registry.Register("application/msgpack", encodeMsgpack)
+	registry.Register("text/plain", encodeText)
+	registry.Register("text/csv", encodeCSV)
+
+	registry.Register("text/xml", encodeXML)
+	registry.Register("application/x-yaml", encodeYAML)
+	registry.Register("text/yaml", encodeYAML)
+	registry.Register("application/x-msgpack", encodeMsgpack)
+	registry.Register("application/vnd.msgpack", encodeMsgpack)
+	return registry
+}
+
+func (r *EncoderRegistry) Register(mediaType string, encoder Encoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mediaType = strings.ToLower(mediaType)
+	if _, exists := r.encoders[mediaType]; !exists {
+		r.order = append(r.order, mediaType)
+	}
+	r.encoders[mediaType] = encoder
+}
+
+func (r *EncoderRegistry) Get(mediaType string) (Encoder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	encoder, exists := r.encoders[strings.ToLower(mediaType)]
+	return encoder, exists
+}
+
+// Negotiate picks the registered media type the Accept header prefers,
+// honouring q-values and type/* wildcards. A type with a structured suffix,
+// such as application/vnd.app.v2+json, is encoded by its base type's
+// encoder and keeps the requested type.
+func (r *EncoderRegistry) Negotiate(accept string) (string, Encoder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, accepted := range parseAccept(accept) {
+		for _, mediaType := range r.order {
+			if mediaTypeMatches(accepted, mediaType) {
+				return mediaType, r.encoders[mediaType], true
+			}
+		}
+		if base, ok := suffixBase(accepted.mediaType); ok {
+			if encoder, exists := r.encoders[base]; exists {
+				return accepted.mediaType, encoder, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+type acceptedType struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(accept string) []acceptedType {
+	if strings.TrimSpace(accept) == "" {
+		return []acceptedType{{mediaType: "*/*", quality: 1}}
+	}
+
+	accepted := make([]acceptedType, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+	return accepted
+}
+
+func mediaTypeMatches(accepted acceptedType, mediaType string) bool {
+	if accepted.mediaType == "*/*" || accepted.mediaType == mediaType {
+		return true
+	}
+	prefix, wildcard := strings.CutSuffix(accepted.mediaType, "/*")
+	return wildcard && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// suffixBase maps a structured syntax suffix to its base type, e.g.
+// application/vnd.app+json to application/json.
+func suffixBase(mediaType string) (string, bool) {
+	kind, subtype, ok := strings.Cut(mediaType, "/")
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndex(subtype, "+")
+	if i < 0 || i == len(subtype)-1 {
+		return "", false
+	}
+	return kind + "/" + subtype[i+1:], true
+}
+
+func encodeJSON(w io.Writer, data any) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// encodeXML wraps slices in an <items> root so the document stays well-formed.
+func encodeXML(w io.Writer, data any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array || value.Type().Elem().Kind() == reflect.Uint8 {
+		return encoder.Encode(data)
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: "items"}}
+	if err := encoder.EncodeToken(root); err != nil {
+		return err
+	}
+	for i := 0; i < value.Len(); i++ {
+		if err := encoder.Encode(value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(root.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func encodeYAML(w io.Writer, data any) error {
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func encodeMsgpack(w io.Writer, data any) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetCustomStructTag("json")
+	return encoder.Encode(data)
+}
+
+func encodeText(w io.Writer, data any) error {
+	_, err := fmt.Fprint(w, data)
+	return err
+}
+
+// encodeCSV accepts [][]string, a slice of structs (columns follow their db
+// tags) or a slice of maps (columns sorted by key).
+func encodeCSV(w io.Writer, data any) error {
+	writer := csv.NewWriter(w)
+	if rows, ok := data.([][]string); ok {
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: %T", ErrUnsupportedCSV, data)
+	}
+
+	elemType := indirectType(value.Type().Elem())
+	var header []string
+	switch {
+	case elemType.Kind() == reflect.Struct && !isScalarType(elemType):
+		header = getStructInfo(elemType).order
+	case elemType.Kind() == reflect.Map && elemType.Key().Kind() == reflect.String:
+		keys := make(map[string]bool)
+		for i := 0; i < value.Len(); i++ {
+			record := reflect.Indirect(value.Index(i))
+			for _, key := range record.MapKeys() {
+				keys[key.String()] = true
+			}
+		}
+		for key := range keys {
+			header = append(header, key)
+		}
+		sort.Strings(header)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedCSV, data)
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < value.Len(); i++ {
+		row := make([]string, len(header))
+		for j, column := range header {
+			if field, ok := recordValue(value.Index(i), column); ok && field != nil {
+				row[j] = fmt.Sprint(field)
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
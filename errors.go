@@ -0,0 +1,156 @@
+package gokit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var ErrPanicRecovered = errors.New("gokit: panic recovered")
+
+type HTTPError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+	Err     error  `json:"-"`
+}
+
+func NewHTTPError(status int, code, message string) *HTTPError {
+	return &HTTPError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %s: %v", e.Status, e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+func (e *HTTPError) WithDetails(details any) *HTTPError {
+	e.Details = details
+	return e
+}
+
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	e.Err = err
+	return e
+}
+
+// ValidationErrors maps each field to its messages. Bind reports fields it
+// could not read with the same map, wrapped in a BindingError.
+type ValidationErrors map[string][]string
+
+func (v ValidationErrors) Add(field, message string) {
+	v[field] = append(v[field], message)
+}
+
+func (v ValidationErrors) Error() string {
+	return "validation failed: " + v.describe()
+}
+
+func (v ValidationErrors) describe() string {
+	fields := make([]string, 0, len(v))
+	for field := range v {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+": "+strings.Join(v[field], ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+type HandlerFuncE func(Context) error
+type ErrorHandlerFunc func(Context, error)
+
+func HandleE(handler HandlerFuncE) HandlerFunc {
+	return func(ctx Context) {
+		if err := handler(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+func ToHTTPError(err error) *HTTPError {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	var validationErr ValidationErrors
+	if errors.As(err, &validationErr) {
+		return NewHTTPError(http.StatusUnprocessableEntity, "validation_failed", "The given data was invalid.").
+			WithDetails(validationErr).
+			Wrap(err)
+	}
+
+	var bindingErr *BindingError
+	if errors.As(err, &bindingErr) {
+		return NewHTTPError(http.StatusBadRequest, "binding_failed", "The request could not be read.").
+			WithDetails(bindingErr.Fields).
+			Wrap(err)
+	}
+
+	if errors.Is(err, ErrRecordNotFound) {
+		return NewHTTPError(http.StatusNotFound, "not_found", "The requested resource was not found.").Wrap(err)
+	}
+
+	return NewHTTPError(http.StatusInternalServerError, "internal_error", http.StatusText(http.StatusInternalServerError)).Wrap(err)
+}
+
+func DefaultErrorHandler(ctx Context, err error) {
+	httpErr := ToHTTPError(err)
+	requestID := RequestID(ctx)
+
+	if httpErr.Status >= http.StatusInternalServerError && !errors.Is(err, ErrPanicRecovered) {
+		slog.Error("request failed",
+			"method", ctx.Request().Method,
+			"path", ctx.Request().URL.Path,
+			"status", httpErr.Status,
+			"request_id", requestID,
+			"error", err,
+		)
+	}
+
+	problem := map[string]any{
+		"type":     "about:blank",
+		"title":    http.StatusText(httpErr.Status),
+		"status":   httpErr.Status,
+		"detail":   httpErr.Message,
+		"code":     httpErr.Code,
+		"instance": ctx.Request().URL.Path,
+	}
+	if httpErr.Details != nil {
+		problem["errors"] = httpErr.Details
+	}
+	if requestID != "" {
+		problem["request_id"] = requestID
+	}
+
+	body, _ := json.Marshal(problem)
+	ctx.Writer().Header().Set("Content-Type", "application/problem+json")
+	ctx.Writer().WriteHeader(httpErr.Status)
+	ctx.Writer().Write(body)
+}
+
+func RequestID(ctx Context) string {
+	if id := ctx.Header("X-Request-ID"); id != "" {
+		return id
+	}
+	return ctx.Writer().Header().Get("X-Request-ID")
+}
@@ -1,9 +1,12 @@
 package gokit
 
+import "sync"
+
 type App struct {
 	*Container
-	providers []ServiceProvider
-	booted    bool
+	providers    []ServiceProvider
+	booted       bool
+	shutdownOnce sync.Once
 }
 
 func (a *App) AddProvider(provider ServiceProvider) {
@@ -22,7 +25,7 @@ func New() Application {
 }
 
 func (a *App) autoRegisterProviders() {
-	a.providers = append(a.providers, &ConfigProvider{}, &RouterProvider{}, &DatabaseProvider{})
+	a.providers = append(a.providers, &ConfigProvider{}, &RouterProvider{}, &ValidationProvider{}, &DatabaseProvider{})
 }
 
 func (a *App) boot() {
@@ -41,6 +44,16 @@ func (a *App) boot() {
 	a.booted = true
 }
 
+func (a *App) Shutdown() {
+	a.shutdownOnce.Do(func() {
+		for i := len(a.providers) - 1; i >= 0; i-- {
+			if provider, ok := a.providers[i].(ShutdownProvider); ok {
+				provider.Shutdown(a)
+			}
+		}
+	})
+}
+
 func (a *App) Config() Config {
 	return a.Make(ConfigBinding).(Config)
 }
@@ -48,6 +61,11 @@ func (a *App) Config() Config {
 func (a *App) Router() Router {
 	return a.Make(RouterBinding).(Router)
 }
+
 func (a *App) DB() Database {
 	return a.Make(DatabaseBinding).(Database)
 }
+
+func (a *App) Validator() Validator {
+	return a.Make(ValidatorBinding).(Validator)
+}
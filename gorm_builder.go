@@ -1,16 +1,110 @@
 package gokit
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type GormQueryBuilder struct {
-	db    *gorm.DB
-	model any
+	db        *gorm.DB
+	ctx       context.Context
+	model     any
+	relations *RelationRegistry
+	scopes    *ScopeRegistry
+	excluded  map[string]bool
+	scoped    bool
+	ctes      []commonTable
 }
 
-func NewGormQueryBuilder(db *gorm.DB, model any) QueryBuilder {
+var bindVarPattern = regexp.MustCompile(`\$\d+`)
+
+func NewGormQueryBuilder(db *gorm.DB, model any, relations *RelationRegistry, scopes *ScopeRegistry) QueryBuilder {
+	if relations == nil {
+		relations = NewRelationRegistry()
+	}
+	if scopes == nil {
+		scopes = NewScopeRegistry()
+	}
 	return &GormQueryBuilder{
-		db:    db,
-		model: model,
+		db:        db,
+		ctx:       context.Background(),
+		model:     model,
+		relations: relations,
+		scopes:    scopes,
+		excluded:  make(map[string]bool),
+	}
+}
+
+func (g *GormQueryBuilder) WithContext(ctx context.Context) QueryBuilder {
+	g.ctx = ctx
+	g.db = g.db.WithContext(ctx)
+	return g
+}
+
+func (g *GormQueryBuilder) WithoutGlobalScope(names ...string) QueryBuilder {
+	for _, name := range names {
+		g.excluded[name] = true
+	}
+	return g
+}
+
+// prepare applies the model's global scopes.
+func (g *GormQueryBuilder) prepare() error {
+	if g.scoped || g.db.Error != nil {
+		return g.db.Error
+	}
+	g.scoped = true
+
+	table, err := g.tableName()
+	if err != nil {
+		g.addError(err)
+		return err
+	}
+
+	conditions := make([]clause.Expression, 0)
+	for _, scope := range g.scopes.For(table, g.excluded) {
+		group := g.newGroup()
+		if err := scope(g.ctx, group); err != nil {
+			g.addError(err)
+			return err
+		}
+		if group.db.Error != nil {
+			g.addError(group.db.Error)
+			return group.db.Error
+		}
+		if where, ok := group.db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			conditions = append(conditions, clause.And(where.Exprs...))
+		}
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	g.db = g.db.Clauses()
+	if where, ok := g.db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		conditions = append([]clause.Expression{clause.And(where.Exprs...)}, conditions...)
+	}
+	g.db.Statement.Clauses["WHERE"] = clause.Clause{Name: "WHERE", Expression: clause.Where{Exprs: conditions}}
+	return nil
+}
+
+func (g *GormQueryBuilder) newGroup() *GormQueryBuilder {
+	return &GormQueryBuilder{
+		db:        g.db.Session(&gorm.Session{NewDB: true}),
+		ctx:       g.ctx,
+		model:     g.model,
+		relations: g.relations,
+		scopes:    g.scopes,
+		excluded:  make(map[string]bool),
+		scoped:    true,
 	}
 }
 
@@ -20,12 +114,95 @@ func (g *GormQueryBuilder) Select(columns ...string) QueryBuilder {
 }
 
 func (g *GormQueryBuilder) Where(query any, args ...any) QueryBuilder {
+	if fn, ok := query.(func(QueryBuilder)); ok {
+		g.db = g.db.Where(g.group(fn))
+		return g
+	}
 	g.db = g.db.Where(query, args...)
 	return g
 }
 
-func (g *GormQueryBuilder) WhereIn(column string, values []any) QueryBuilder {
-	g.db = g.db.Where(column+" IN ?", values)
+func (g *GormQueryBuilder) OrWhere(query any, args ...any) QueryBuilder {
+	if fn, ok := query.(func(QueryBuilder)); ok {
+		g.db = g.db.Or(g.group(fn))
+		return g
+	}
+	g.db = g.db.Or(query, args...)
+	return g
+}
+
+func (g *GormQueryBuilder) WhereNot(query any, args ...any) QueryBuilder {
+	if fn, ok := query.(func(QueryBuilder)); ok {
+		g.db = g.db.Not(g.group(fn))
+		return g
+	}
+	g.db = g.db.Not(query, args...)
+	return g
+}
+
+func (g *GormQueryBuilder) WhereIn(column string, values any) QueryBuilder {
+	builder, ok := values.(QueryBuilder)
+	if !ok {
+		return g.columnWhere(column, " IN ?", values)
+	}
+
+	query, args, err := g.subquerySQL(builder)
+	if err != nil {
+		return g.addError(err)
+	}
+	return g.columnWhere(column, " IN ("+query+")", args...)
+}
+
+func (g *GormQueryBuilder) WhereNull(column string) QueryBuilder {
+	return g.columnWhere(column, " IS NULL")
+}
+
+func (g *GormQueryBuilder) WhereNotNull(column string) QueryBuilder {
+	return g.columnWhere(column, " IS NOT NULL")
+}
+
+func (g *GormQueryBuilder) WhereBetween(column string, from, to any) QueryBuilder {
+	return g.columnWhere(column, " BETWEEN ? AND ?", from, to)
+}
+
+func (g *GormQueryBuilder) WhereLike(column string, pattern string) QueryBuilder {
+	return g.columnWhere(column, " LIKE ?", pattern)
+}
+
+func (g *GormQueryBuilder) WhereColumn(first, operator, second string) QueryBuilder {
+	op, err := comparisonOperator(operator)
+	if err != nil {
+		return g.addError(err)
+	}
+
+	other, err := quoteIdentifier(second)
+	if err != nil {
+		return g.addError(err)
+	}
+	return g.columnWhere(first, " "+op+" "+other)
+}
+
+func (g *GormQueryBuilder) columnWhere(column, condition string, args ...any) QueryBuilder {
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		return g.addError(err)
+	}
+	g.db = g.db.Where(quoted+condition, args...)
+	return g
+}
+
+func (g *GormQueryBuilder) group(fn func(QueryBuilder)) *gorm.DB {
+	group := g.newGroup()
+	fn(group)
+	if group.db.Error != nil {
+		g.addError(group.db.Error)
+	}
+	return group.db
+}
+
+func (g *GormQueryBuilder) addError(err error) QueryBuilder {
+	g.db = g.db.Session(&gorm.Session{})
+	g.db.AddError(err)
 	return g
 }
 
@@ -35,17 +212,27 @@ func (g *GormQueryBuilder) Join(query string, args ...any) QueryBuilder {
 }
 
 func (g *GormQueryBuilder) OrderBy(column string, direction ...string) QueryBuilder {
-	dir := "ASC"
-	if len(direction) > 0 {
-		dir = direction[0]
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		return g.addError(err)
 	}
-	g.db = g.db.Order(column + " " + dir)
+
+	dir, err := sortDirection(direction...)
+	if err != nil {
+		return g.addError(err)
+	}
+
+	g.db = g.db.Order(quoted + " " + dir)
 	return g
 }
 
 func (g *GormQueryBuilder) GroupBy(columns ...string) QueryBuilder {
-	for _, col := range columns {
-		g.db = g.db.Group(col)
+	for _, column := range columns {
+		quoted, err := quoteIdentifier(column)
+		if err != nil {
+			return g.addError(err)
+		}
+		g.db = g.db.Group(quoted)
 	}
 	return g
 }
@@ -65,6 +252,113 @@ func (g *GormQueryBuilder) Offset(offset int) QueryBuilder {
 	return g
 }
 
+func (g *GormQueryBuilder) FromSub(builder QueryBuilder, alias string) QueryBuilder {
+	quoted, err := quoteIdentifier(alias)
+	if err != nil {
+		return g.addError(err)
+	}
+
+	query, args, err := g.subquerySQL(builder)
+	if err != nil {
+		return g.addError(err)
+	}
+
+	g.db = g.db.Table("("+query+") AS "+quoted, args...)
+	return g
+}
+
+func (g *GormQueryBuilder) WithCTE(name string, builder QueryBuilder) QueryBuilder {
+	return g.addCommonTable(name, builder, false)
+}
+
+func (g *GormQueryBuilder) WithRecursive(name string, builder QueryBuilder) QueryBuilder {
+	return g.addCommonTable(name, builder, true)
+}
+
+func (g *GormQueryBuilder) Union(builder QueryBuilder) QueryBuilder {
+	return g.addUnion(builder, false)
+}
+
+func (g *GormQueryBuilder) UnionAll(builder QueryBuilder) QueryBuilder {
+	return g.addUnion(builder, true)
+}
+
+// addCommonTable renders the WITH list in front of the SELECT clause, which
+// GORM has no dedicated API for.
+func (g *GormQueryBuilder) addCommonTable(name string, builder QueryBuilder, recursive bool) QueryBuilder {
+	quoted, err := quoteIdentifier(name)
+	if err != nil {
+		return g.addError(err)
+	}
+
+	sub, err := toSubquery(builder)
+	if err != nil {
+		return g.addError(err)
+	}
+	g.ctes = append(g.ctes, commonTable{name: quoted, recursive: recursive, query: sub})
+
+	query, args, err := withCommonTables(g.ctes, sq.Expr("")).ToSql()
+	if err != nil {
+		return g.addError(err)
+	}
+
+	g.db = g.db.Clauses()
+	selectClause := g.db.Statement.Clauses["SELECT"]
+	selectClause.BeforeExpression = clause.Expr{SQL: strings.TrimSpace(query), Vars: args}
+	g.db.Statement.Clauses["SELECT"] = selectClause
+	return g
+}
+
+// addUnion replaces the current query with a derived table named after the
+// model's table, so the union result can be filtered and scanned like it.
+func (g *GormQueryBuilder) addUnion(builder QueryBuilder, all bool) QueryBuilder {
+	table, err := g.tableName()
+	if err != nil {
+		return g.addError(err)
+	}
+
+	current, err := g.subquery()
+	if err != nil {
+		return g.addError(err)
+	}
+
+	other, err := toSubquery(builder)
+	if err != nil {
+		return g.addError(err)
+	}
+
+	query, args, err := withUnions([]union{{all: all, query: other}}, current).ToSql()
+	if err != nil {
+		return g.addError(err)
+	}
+
+	g.ctes = nil
+	g.db = g.db.Session(&gorm.Session{NewDB: true}).Table("("+query+") AS "+table, args...)
+	return g
+}
+
+func (g *GormQueryBuilder) subquerySQL(builder QueryBuilder) (string, []any, error) {
+	sub, err := toSubquery(builder)
+	if err != nil {
+		return "", nil, err
+	}
+	return sub.ToSql()
+}
+
+func (g *GormQueryBuilder) subquery() (sq.Sqlizer, error) {
+	if err := g.prepare(); err != nil {
+		return nil, err
+	}
+
+	stmt := g.db.Session(&gorm.Session{DryRun: true}).Find(g.model)
+	if stmt.Error != nil {
+		return nil, stmt.Error
+	}
+
+	query := bindVarPattern.ReplaceAllString(stmt.Statement.SQL.String(), "?")
+	return sq.Expr(query, stmt.Statement.Vars...), nil
+}
+
 func (g *GormQueryBuilder) Preload(query string, args ...any) QueryBuilder {
 	g.db = g.db.Preload(query, args...)
 	return g
@@ -75,11 +369,57 @@ func (g *GormQueryBuilder) Joins(query string, args ...any) QueryBuilder {
 	return g
 }
 
+func (g *GormQueryBuilder) With(relations ...string) QueryBuilder {
+	for _, relation := range relations {
+		fields := strings.Split(relation, ".")
+		for i, field := range fields {
+			fields[i] = toCamelCase(field)
+		}
+		g.db = g.db.Preload(strings.Join(fields, "."))
+	}
+	return g
+}
+
+func (g *GormQueryBuilder) WhereHas(relation string, fn func(QueryBuilder)) QueryBuilder {
+	table, err := g.tableName()
+	if err != nil {
+		return g.addError(err)
+	}
+
+	builder := newTableQueryBuilder(nil, table, g.relations, g.scopes)
+	builder.ctx = g.ctx
+	exists, err := builder.existsClause(table, relation, fn)
+	if err != nil {
+		return g.addError(err)
+	}
+
+	query, args, err := exists.ToSql()
+	if err != nil {
+		return g.addError(err)
+	}
+	g.db = g.db.Where(query, args...)
+	return g
+}
+
+func (g *GormQueryBuilder) tableName() (string, error) {
+	stmt := &gorm.Statement{DB: g.db}
+	if err := stmt.Parse(g.model); err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
+
 func (g *GormQueryBuilder) Find(dest any) error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
 	return g.db.Find(dest).Error
 }
 
 func (g *GormQueryBuilder) First(dest any) error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
 	return g.db.First(dest).Error
 }
 
@@ -88,25 +428,138 @@ func (g *GormQueryBuilder) Create(value any) error {
 }
 
 func (g *GormQueryBuilder) Update(values any) error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
 	return g.db.Updates(values).Error
 }
 
 func (g *GormQueryBuilder) Delete() error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
 	return g.db.Delete(g.model).Error
 }
 
 func (g *GormQueryBuilder) Count() (int64, error) {
+	if err := g.prepare(); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err := g.query().Count(&count).Error
+	return count, err
+}
+
+func (g *GormQueryBuilder) CountDistinct(column string) (int64, error) {
 	var count int64
-	err := g.db.Model(g.model).Count(&count).Error
+	err := g.aggregate("COUNT(DISTINCT %s)", column, &count)
 	return count, err
 }
 
+func (g *GormQueryBuilder) Sum(column string) (float64, error) {
+	return g.aggregateFloat("SUM", column)
+}
+
+func (g *GormQueryBuilder) Avg(column string) (float64, error) {
+	return g.aggregateFloat("AVG", column)
+}
+
+func (g *GormQueryBuilder) Min(column string) (float64, error) {
+	return g.aggregateFloat("MIN", column)
+}
+
+func (g *GormQueryBuilder) Max(column string) (float64, error) {
+	return g.aggregateFloat("MAX", column)
+}
+
+func (g *GormQueryBuilder) Exists() (bool, error) {
+	if err := g.prepare(); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	subquery := g.query().Select("1")
+	err := g.db.Session(&gorm.Session{NewDB: true}).Raw("SELECT EXISTS (?)", subquery).Scan(&exists).Error
+	return exists, err
+}
+
+func (g *GormQueryBuilder) Pluck(column string, dest any) error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
+	if _, err := quoteIdentifier(column); err != nil {
+		return err
+	}
+	return g.query().Pluck(column, dest).Error
+}
+
+func (g *GormQueryBuilder) Value(column string, dest any) error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
+
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		return err
+	}
+
+	err = g.query().Select(quoted).Limit(1).Row().Scan(dest)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRecordNotFound
+	}
+	return err
+}
+
+func (g *GormQueryBuilder) aggregateFloat(function, column string) (float64, error) {
+	var result sql.NullFloat64
+	err := g.aggregate(function+"(%s)", column, &result)
+	return result.Float64, err
+}
+
+// aggregate follows TableQueryBuilder.aggregate: over a grouped query,
+// column must be one the query selects.
+func (g *GormQueryBuilder) aggregate(format, column string, dest any) error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
+
+	query := g.query()
+	if _, grouped := query.Statement.Clauses["GROUP BY"]; grouped {
+		if len(query.Statement.Selects) == 0 {
+			return fmt.Errorf("%w: %s", ErrAggregateNotSelected, column)
+		}
+		reference, err := aggregatedColumn(query.Statement.Selects, column)
+		if err != nil {
+			return err
+		}
+		return g.db.Session(&gorm.Session{NewDB: true}).
+			Table("(?) AS aggregated", query).
+			Select(fmt.Sprintf(format, reference)).
+			Row().
+			Scan(dest)
+	}
+
+	reference, err := aggregateColumn(column)
+	if err != nil {
+		return err
+	}
+	delete(query.Statement.Clauses, "ORDER BY")
+	return query.Select(fmt.Sprintf(format, reference)).Row().Scan(dest)
+}
+
+// query returns a copy of the builder's statement, so helpers that replace
+// the SELECT do not leave it behind for later calls.
+func (g *GormQueryBuilder) query() *gorm.DB {
+	return g.db.Session(&gorm.Session{}).Model(g.model)
+}
+
 func (g *GormQueryBuilder) Begin() (QueryBuilder, error) {
 	tx := g.db.Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
-	return NewGormQueryBuilder(tx, g.model), nil
+	return NewGormQueryBuilder(tx, g.model, g.relations, g.scopes), nil
 }
 
 func (g *GormQueryBuilder) Commit() error {
@@ -118,10 +571,15 @@ func (g *GormQueryBuilder) Rollback() error {
 }
 
 func (g *GormQueryBuilder) ToSQL() (string, []any, error) {
-	stmt := &gorm.Statement{DB: g.db}
-	g.db.Statement = stmt
+	statement, err := g.subquery()
+	if err != nil {
+		return "", nil, err
+	}
 
-	return g.db.ToSQL(func(tx *gorm.DB) *gorm.DB {
-		return tx.Find(g.model)
-	}), nil, nil
+	query, args, err := statement.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	query, err = sq.Dollar.ReplacePlaceholders(query)
+	return query, args, err
 }
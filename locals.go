@@ -0,0 +1,63 @@
+package gokit
+
+import (
+	"context"
+	"fmt"
+)
+
+// LocalKey looks up a value stored with Ctx.Set through context.Context, so
+// code that only receives ctx.Context() can read it.
+type LocalKey string
+
+func (c *Ctx) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.locals == nil {
+		c.locals = make(map[string]any)
+	}
+	c.locals[key] = value
+}
+
+func (c *Ctx) Get(key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, exists := c.locals[key]
+	return value, exists
+}
+
+func (c *Ctx) MustGet(key string) any {
+	value, exists := c.Get(key)
+	if !exists {
+		panic(fmt.Sprintf("gokit: key %q does not exist in context", key))
+	}
+	return value
+}
+
+// Context returns the request's context.Context with the values set on c
+// visible through Value(LocalKey(key)).
+func (c *Ctx) Context() context.Context {
+	return localsContext{Context: c.request.Context(), ctx: c}
+}
+
+type localsContext struct {
+	context.Context
+	ctx *Ctx
+}
+
+func (l localsContext) Value(key any) any {
+	if k, ok := key.(LocalKey); ok {
+		if value, exists := l.ctx.Get(string(k)); exists {
+			return value
+		}
+	}
+	return l.Context.Value(key)
+}
+
+// Local returns the value stored under key when it is a T.
+func Local[T any](ctx Context, key string) (T, bool) {
+	value, _ := ctx.Get(key)
+	typed, ok := value.(T)
+	return typed, ok
+}
@@ -0,0 +1,64 @@
+package gokit
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+)
+
+type RecoverConfig struct {
+	Repanic bool
+	Logger  *slog.Logger
+}
+
+func Recover() MiddlewareFunc {
+	return RecoverWithConfig(RecoverConfig{})
+}
+
+func RecoverWithConfig(config RecoverConfig) MiddlewareFunc {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	return func(ctx Context, next Next) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+			err = fmt.Errorf("%w: %w", ErrPanicRecovered, err)
+
+			config.Logger.Error("panic recovered",
+				"method", ctx.Request().Method,
+				"path", ctx.Request().URL.Path,
+				"request_id", RequestID(ctx),
+				"error", err,
+				"stack", string(debug.Stack()),
+			)
+
+			ctx.Error(NewHTTPError(http.StatusInternalServerError, "internal_error", http.StatusText(http.StatusInternalServerError)).Wrap(err))
+
+			if config.Repanic {
+				// Send the error response now: re-panicking skips the
+				// commit at the end of the chain.
+				if header := ctx.Writer().Header(); header.Get("Content-Length") == "" {
+					header.Set("Content-Length", strconv.Itoa(len(ctx.Response().Body())))
+				}
+				http.NewResponseController(ctx.Writer()).Flush()
+				panic(recovered)
+			}
+		}()
+
+		next()
+	}
+}
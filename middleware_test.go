@@ -0,0 +1,43 @@
+package gokit
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecoverRepanicStillResponds(t *testing.T) {
+	r := NewRouter()
+	r.Use(RecoverWithConfig(RecoverConfig{
+		Repanic: true,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}))
+	r.GET("/boom", func(ctx Context) {
+		ctx.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	srv := httptest.NewUnstartedServer(r.Handler())
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/boom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/problem+json" {
+		t.Fatalf("Content-Type = %q, body = %s", got, body)
+	}
+}
@@ -0,0 +1,26 @@
+package gokit
+
+import (
+	"net/http"
+	"strings"
+)
+
+const mountMethod = "*"
+
+// Mount serves handler for every method under prefix, with the prefix
+// stripped from the request path. The router's middleware still runs.
+func (r *router) Mount(prefix string, handler http.Handler, middleware ...MiddlewareFunc) *Route {
+	prefix = "/" + strings.Trim(prefix, "/")
+	fullPrefix := strings.TrimSuffix(r.prefix+prefix, "/")
+	stripped := http.StripPrefix(fullPrefix, handler)
+
+	return r.handle(mountMethod, prefix+"/", func(ctx Context) {
+		stripped.ServeHTTP(ctx.Writer(), ctx.Request())
+	}, middleware)
+}
+
+// Handler returns the router as an http.Handler, for embedding in another
+// server or serving with httptest without calling Listen.
+func (r *router) Handler() http.Handler {
+	return r.root
+}
@@ -0,0 +1,188 @@
+package gokit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidParam = errors.New("gokit: invalid parameter")
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var paramConstraints = map[string]*regexp.Regexp{
+	"int":   regexp.MustCompile(`^-?[0-9]+$`),
+	"uint":  regexp.MustCompile(`^[0-9]+$`),
+	"alpha": regexp.MustCompile(`^[A-Za-z]+$`),
+	"alnum": regexp.MustCompile(`^[A-Za-z0-9]+$`),
+	"slug":  regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`),
+	"uuid":  uuidPattern,
+}
+
+type routePattern struct {
+	path        string
+	params      []string
+	constraints map[string]*regexp.Regexp
+	keys        map[string]string
+}
+
+// parsePattern strips {name=column:constraint} declarations from path,
+// leaving the plain ServeMux pattern. The optional column is the key used when
+// the parameter is bound to a model. A constraint is either a built-in name or
+// a regular expression that must match the whole segment.
+func parsePattern(path string) (routePattern, error) {
+	var b strings.Builder
+	pattern := routePattern{
+		constraints: make(map[string]*regexp.Regexp),
+		keys:        make(map[string]string),
+	}
+
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			b.WriteByte(path[i])
+			continue
+		}
+
+		depth, end := 0, -1
+		for j := i; j < len(path) && end < 0; j++ {
+			switch path[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			return routePattern{}, fmt.Errorf("gokit: unclosed parameter in %q", path)
+		}
+
+		name, constraint, constrained := strings.Cut(path[i+1:end], ":")
+		name, key, keyed := strings.Cut(name, "=")
+		b.WriteString("{" + name + "}")
+		i = end
+
+		param := strings.TrimSuffix(name, "...")
+		pattern.params = append(pattern.params, param)
+		if keyed {
+			if !keyPattern.MatchString(key) {
+				return routePattern{}, fmt.Errorf("gokit: invalid key column %q for %q in %q", key, param, path)
+			}
+			pattern.keys[param] = key
+		}
+		if !constrained {
+			continue
+		}
+		if matcher, ok := paramConstraints[constraint]; ok {
+			pattern.constraints[param] = matcher
+			continue
+		}
+		if keyPattern.MatchString(constraint) {
+			return routePattern{}, fmt.Errorf("gokit: unknown constraint %q for %q in %q; use {%s=%s} to bind by a column",
+				constraint, param, path, param, constraint)
+		}
+		matcher, err := regexp.Compile("^(?:" + constraint + ")$")
+		if err != nil {
+			return routePattern{}, fmt.Errorf("gokit: invalid constraint for %q in %q: %w", name, path, err)
+		}
+		pattern.constraints[param] = matcher
+	}
+	pattern.path = b.String()
+	return pattern, nil
+}
+
+func (route *Route) matches(req *http.Request) bool {
+	for name, matcher := range route.constraints {
+		if !matcher.MatchString(req.PathValue(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func invalidParam(key, expected string, err error) error {
+	return NewHTTPError(http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("The %s parameter must be %s.", key, expected)).
+		Wrap(fmt.Errorf("%w: %s: %w", ErrInvalidParam, key, err))
+}
+
+func (c *Ctx) ParamInt(key string) (int, error) {
+	value, err := strconv.Atoi(c.Param(key))
+	if err != nil {
+		return 0, invalidParam(key, "an integer", err)
+	}
+	return value, nil
+}
+
+func (c *Ctx) ParamUUID(key string) (string, error) {
+	value := c.Param(key)
+	if !uuidPattern.MatchString(value) {
+		return "", invalidParam(key, "a UUID", fmt.Errorf("%q is not a UUID", value))
+	}
+	return strings.ToLower(value), nil
+}
+
+func (c *Ctx) QueryInt(key string, def int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return def, invalidParam(key, "an integer", err)
+	}
+	return value, nil
+}
+
+func (c *Ctx) QueryBool(key string, def bool) (bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+	switch strings.ToLower(raw) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return def, invalidParam(key, "a boolean", fmt.Errorf("%q is not a boolean", raw))
+}
+
+// QuerySlice accepts both repeated keys (?tag=a&tag=b) and comma-separated
+// values (?tag=a,b).
+func (c *Ctx) QuerySlice(key string) []string {
+	values := make([]string, 0)
+	for _, raw := range c.request.URL.Query()[key] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// QueryTime parses the value with the given layout, RFC 3339 by default.
+func (c *Ctx) QueryTime(key string, def time.Time, layout ...string) (time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+
+	format := time.RFC3339
+	if len(layout) > 0 {
+		format = layout[0]
+	}
+	value, err := time.Parse(format, raw)
+	if err != nil {
+		return def, invalidParam(key, "a time in "+format+" format", err)
+	}
+	return value, nil
+}
@@ -1,6 +1,7 @@
 package gokit
 
 import (
+	"log/slog"
 	"os"
 )
 
@@ -22,11 +23,35 @@ type RouterProvider struct{}
 
 func (p *RouterProvider) Register(app Application) {
 	app.Singleton(RouterBinding, func() any {
-		return NewRouter()
+		router := NewRouterWithConfig(app.Config())
+		if key := app.Config().Get("APP_KEY"); key != "" {
+			router.SetSigningKey([]byte(key))
+		}
+		router.Use(RecoverWithConfig(RecoverConfig{
+			Repanic: app.Config().GetWithDefault("APP_ENV", "production") == "development",
+		}))
+		return router
+	})
+}
+
+func (p *RouterProvider) Boot(app Application) {
+	app.Router().OnShutdown(app.Shutdown)
+}
+
+// ValidationProvider shares one Validator between the container and the
+// router, so rules and messages registered on app.Validator() apply to
+// ctx.BindAndValidate.
+type ValidationProvider struct{}
+
+func (p *ValidationProvider) Register(app Application) {
+	app.Singleton(ValidatorBinding, func() any {
+		return NewValidator()
 	})
 }
 
-func (p *RouterProvider) Boot(app Application) {}
+func (p *ValidationProvider) Boot(app Application) {
+	app.Router().SetValidator(app.Validator())
+}
 
 type DatabaseProvider struct{}
 
@@ -39,5 +64,12 @@ func (p *DatabaseProvider) Register(app Application) {
 }
 
 func (p *DatabaseProvider) Boot(app Application) {
-	app.Make(DatabaseBinding)
+	app.Router().SetDatabase(app.DB())
+	app.Validator().SetDatabase(app.DB())
+}
+
+func (p *DatabaseProvider) Shutdown(app Application) {
+	if err := app.DB().Close(); err != nil {
+		slog.Error("failed to close database connection", "error", err)
+	}
 }
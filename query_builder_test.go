@@ -0,0 +1,254 @@
+package gokit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recorder is a database/sql driver that logs every statement and answers
+// each query with a single row holding 1, which scans into the counts, sums,
+// flags and ids the builders read.
+type recorder struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return r }
+func (r *recorder) Open(string) (driver.Conn, error)             { return recorderConn{r}, nil }
+
+func (r *recorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.queries) == 0 {
+		return ""
+	}
+	return r.queries[len(r.queries)-1]
+}
+
+type recorderConn struct{ recorder *recorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{c.recorder, query}, nil
+}
+func (c recorderConn) Close() error              { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) { return c, nil }
+func (c recorderConn) Commit() error             { return nil }
+func (c recorderConn) Rollback() error           { return nil }
+
+type recorderStmt struct {
+	recorder *recorder
+	query    string
+}
+
+func (s recorderStmt) Close() error  { return nil }
+func (s recorderStmt) NumInput() int { return -1 }
+
+func (s recorderStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.record()
+	return driver.RowsAffected(1), nil
+}
+
+func (s recorderStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.record()
+	return &recorderRows{}, nil
+}
+
+func (s recorderStmt) record() {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.queries = append(s.recorder.queries, s.query)
+}
+
+type recorderRows struct{ done bool }
+
+func (r *recorderRows) Columns() []string { return []string{"id"} }
+func (r *recorderRows) Close() error      { return nil }
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func newRecordedDB(t *testing.T) (*DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	sqlDB := sql.OpenDB(rec)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &DB{
+		conn:      &Connection{sqlDB: sqlDB, gormDB: gormDB},
+		relations: NewRelationRegistry(),
+		scopes:    NewScopeRegistry(),
+	}, rec
+}
+
+type order struct {
+	ID     int
+	Status string
+	Amount float64
+}
+
+func TestQueryBuilderReuseAfterHelpers(t *testing.T) {
+	helpers := map[string]func(QueryBuilder) error{
+		"Count":         func(q QueryBuilder) error { _, err := q.Count(); return err },
+		"CountDistinct": func(q QueryBuilder) error { _, err := q.CountDistinct("status"); return err },
+		"Sum":           func(q QueryBuilder) error { _, err := q.Sum("amount"); return err },
+		"Avg":           func(q QueryBuilder) error { _, err := q.Avg("amount"); return err },
+		"Min":           func(q QueryBuilder) error { _, err := q.Min("amount"); return err },
+		"Max":           func(q QueryBuilder) error { _, err := q.Max("amount"); return err },
+		"Exists":        func(q QueryBuilder) error { _, err := q.Exists(); return err },
+		"Pluck":         func(q QueryBuilder) error { var ids []int; return q.Pluck("id", &ids) },
+		"Value":         func(q QueryBuilder) error { var id int; return q.Value("id", &id) },
+	}
+	builders := map[string]func(*DB) QueryBuilder{
+		"model": func(db *DB) QueryBuilder { return db.Model(&order{}) },
+		"table": func(db *DB) QueryBuilder { return db.Table("orders") },
+	}
+
+	for builderName, newBuilder := range builders {
+		db, rec := newRecordedDB(t)
+		var rows []order
+		if err := newBuilder(db).Where("status = ?", "paid").OrderBy("id").Find(&rows); err != nil {
+			t.Fatal(err)
+		}
+		want := rec.last()
+
+		for helperName, helper := range helpers {
+			t.Run(builderName+"/"+helperName, func(t *testing.T) {
+				query := newBuilder(db).Where("status = ?", "paid").OrderBy("id")
+				if err := helper(query); err != nil {
+					t.Fatal(err)
+				}
+				if err := query.Find(&rows); err != nil {
+					t.Fatal(err)
+				}
+				if got := rec.last(); got != want {
+					t.Fatalf("Find after %s ran\n%s\nwant\n%s", helperName, got, want)
+				}
+
+				if _, err := query.Count(); err != nil {
+					t.Fatal(err)
+				}
+				countAfter := rec.last()
+				if _, err := newBuilder(db).Where("status = ?", "paid").OrderBy("id").Count(); err != nil {
+					t.Fatal(err)
+				}
+				if got := rec.last(); got != countAfter {
+					t.Fatalf("Count after %s ran\n%s\nwant\n%s", helperName, countAfter, got)
+				}
+			})
+		}
+	}
+}
+
+func TestGroupedAggregates(t *testing.T) {
+	tests := map[string]struct {
+		newBuilder func(*DB) QueryBuilder
+		want       string
+	}{
+		"model": {
+			newBuilder: func(db *DB) QueryBuilder { return db.Model(&order{}) },
+			want:       `SELECT AVG("total") FROM (SELECT customer_id,SUM(amount) AS total FROM "orders" GROUP BY "customer_id") AS aggregated`,
+		},
+		"table": {
+			newBuilder: func(db *DB) QueryBuilder { return db.Table("orders") },
+			want:       `SELECT AVG("total") FROM (SELECT customer_id, SUM(amount) AS total FROM orders GROUP BY "customer_id") AS aggregated`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, rec := newRecordedDB(t)
+
+			if _, err := tt.newBuilder(db).Select("customer_id", "SUM(amount) AS total").GroupBy("customer_id").Avg("total"); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.last(); got != tt.want {
+				t.Fatalf("query = %s, want %s", got, tt.want)
+			}
+
+			_, err := tt.newBuilder(db).GroupBy("customer_id").Sum("amount")
+			if !errors.Is(err, ErrAggregateNotSelected) {
+				t.Fatalf("Sum over an unselected column: error = %v, want ErrAggregateNotSelected", err)
+			}
+		})
+	}
+}
+
+func TestColumnArgumentsRejectInjection(t *testing.T) {
+	const column = "amount) FROM users; --"
+	calls := map[string]func(QueryBuilder) error{
+		"CountDistinct": func(q QueryBuilder) error { _, err := q.CountDistinct(column); return err },
+		"Sum":           func(q QueryBuilder) error { _, err := q.Sum(column); return err },
+		"Avg":           func(q QueryBuilder) error { _, err := q.Avg(column); return err },
+		"Min":           func(q QueryBuilder) error { _, err := q.Min(column); return err },
+		"Max":           func(q QueryBuilder) error { _, err := q.Max(column); return err },
+		"Pluck":         func(q QueryBuilder) error { var values []int; return q.Pluck(column, &values) },
+		"Value":         func(q QueryBuilder) error { var value int; return q.Value(column, &value) },
+		"GroupBy":       func(q QueryBuilder) error { var rows []order; return q.GroupBy(column).Find(&rows) },
+	}
+	builders := map[string]func(*DB) QueryBuilder{
+		"model": func(db *DB) QueryBuilder { return db.Model(&order{}) },
+		"table": func(db *DB) QueryBuilder { return db.Table("orders") },
+	}
+
+	for builderName, newBuilder := range builders {
+		for name, call := range calls {
+			t.Run(builderName+"/"+name, func(t *testing.T) {
+				db, rec := newRecordedDB(t)
+				if err := call(newBuilder(db)); !errors.Is(err, ErrInvalidIdentifier) {
+					t.Fatalf("error = %v, want ErrInvalidIdentifier", err)
+				}
+				if query := rec.last(); query != "" {
+					t.Fatalf("ran %s", query)
+				}
+			})
+		}
+	}
+}
+
+func TestTxWithoutTableFails(t *testing.T) {
+	calls := map[string]func(Transaction) error{
+		"Count":  func(tx Transaction) error { _, err := tx.Count(); return err },
+		"Find":   func(tx Transaction) error { var rows []order; return tx.Find(&rows) },
+		"ToSQL":  func(tx Transaction) error { _, _, err := tx.ToSQL(); return err },
+		"Where":  func(tx Transaction) error { var rows []order; return tx.Where("status = ?", "paid").Find(&rows) },
+		"Select": func(tx Transaction) error { _, err := tx.Select("id").Count(); return err },
+		"Update": func(tx Transaction) error { return tx.WhereIn("id", []int{1}).Update(map[string]any{"status": "paid"}) },
+		"Delete": func(tx Transaction) error { return tx.WhereNotNull("status").Delete() },
+		"Exists": func(tx Transaction) error { _, err := tx.OrderBy("id").Limit(1).Exists(); return err },
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			db, rec := newRecordedDB(t)
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback()
+
+			if err := call(tx); !errors.Is(err, ErrTxNoTable) {
+				t.Fatalf("error = %v, want ErrTxNoTable", err)
+			}
+			if query := rec.last(); query != "" {
+				t.Fatalf("ran %s", query)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package gokit
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type RelationType int
+
+const (
+	HasOneRelation RelationType = iota
+	HasManyRelation
+	BelongsToRelation
+	ManyToManyRelation
+)
+
+const pivotKeyAlias = "gokit_pivot_key"
+
+type Relation struct {
+	Type  RelationType
+	Table string
+
+	ForeignKey string
+	LocalKey   string
+	OwnerKey   string
+
+	Pivot           string
+	ForeignPivotKey string
+	RelatedPivotKey string
+	RelatedKey      string
+}
+
+func HasOne(table, foreignKey, localKey string) Relation {
+	return Relation{Type: HasOneRelation, Table: table, ForeignKey: foreignKey, LocalKey: localKey}
+}
+
+func HasMany(table, foreignKey, localKey string) Relation {
+	return Relation{Type: HasManyRelation, Table: table, ForeignKey: foreignKey, LocalKey: localKey}
+}
+
+func BelongsTo(table, foreignKey, ownerKey string) Relation {
+	return Relation{Type: BelongsToRelation, Table: table, ForeignKey: foreignKey, OwnerKey: ownerKey}
+}
+
+func ManyToMany(table, pivot, foreignPivotKey, relatedPivotKey string) Relation {
+	return Relation{
+		Type:            ManyToManyRelation,
+		Table:           table,
+		Pivot:           pivot,
+		ForeignPivotKey: foreignPivotKey,
+		RelatedPivotKey: relatedPivotKey,
+		LocalKey:        "id",
+		RelatedKey:      "id",
+	}
+}
+
+func (r Relation) parentKey() string {
+	if r.Type == BelongsToRelation {
+		return r.ForeignKey
+	}
+	return r.LocalKey
+}
+
+func (r Relation) relatedKey() string {
+	switch r.Type {
+	case BelongsToRelation:
+		return r.OwnerKey
+	case ManyToManyRelation:
+		return r.RelatedKey
+	}
+	return r.ForeignKey
+}
+
+func (r Relation) many() bool {
+	return r.Type == HasManyRelation || r.Type == ManyToManyRelation
+}
+
+type RelationRegistry struct {
+	relations map[string]map[string]Relation
+	mu        sync.Mutex
+}
+
+func NewRelationRegistry() *RelationRegistry {
+	return &RelationRegistry{
+		relations: make(map[string]map[string]Relation),
+	}
+}
+
+func (r *RelationRegistry) Define(table, name string, relation Relation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.relations[table] == nil {
+		r.relations[table] = make(map[string]Relation)
+	}
+	r.relations[table][name] = relation
+}
+
+func (r *RelationRegistry) Get(table, name string) (Relation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	relation, exists := r.relations[table][name]
+	if !exists {
+		return Relation{}, fmt.Errorf("gokit: relation %q is not defined on table %q", name, table)
+	}
+	return relation, nil
+}
+
+type eagerTree map[string]eagerTree
+
+func buildEagerTree(paths []string) eagerTree {
+	tree := make(eagerTree)
+	for _, path := range paths {
+		node := tree
+		for _, name := range strings.Split(path, ".") {
+			if node[name] == nil {
+				node[name] = make(eagerTree)
+			}
+			node = node[name]
+		}
+	}
+	return tree
+}
+
+func (t *TableQueryBuilder) loadRelations(parents reflect.Value, table string, tree eagerTree) error {
+	for name, nested := range tree {
+		if err := t.loadRelation(parents, table, name, nested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TableQueryBuilder) loadRelation(parents reflect.Value, table, name string, nested eagerTree) error {
+	relation, err := t.relations.Get(table, name)
+	if err != nil {
+		return err
+	}
+
+	childType, err := relationElemType(parents.Type().Elem(), name, relation)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]any, 0, parents.Len())
+	seen := make(map[string]bool)
+	for i := 0; i < parents.Len(); i++ {
+		value, ok := recordValue(parents.Index(i), relation.parentKey())
+		if !ok {
+			continue
+		}
+		if key, ok := keyString(value); ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, value)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query := t.newQuery(relation.Table)
+	matchColumn := relation.relatedKey()
+	if relation.Type == ManyToManyRelation {
+		query.columns = []string{
+			relation.Table + ".*",
+			fmt.Sprintf("%s.%s AS %s", relation.Pivot, relation.ForeignPivotKey, pivotKeyAlias),
+		}
+		query.joins = append(query.joins, sq.Expr(fmt.Sprintf("JOIN %s ON %s.%s = %s.%s",
+			relation.Pivot, relation.Pivot, relation.RelatedPivotKey, relation.Table, relation.RelatedKey)))
+		query.Where(sq.Eq{relation.Pivot + "." + relation.ForeignPivotKey: keys})
+		matchColumn = pivotKeyAlias
+	} else {
+		query.Where(sq.Eq{relation.Table + "." + matchColumn: keys})
+	}
+
+	children, extras, err := query.fetch(childType)
+	if err != nil {
+		return err
+	}
+
+	if len(nested) > 0 && children.Len() > 0 {
+		if err := t.loadRelations(children, relation.Table, nested); err != nil {
+			return err
+		}
+	}
+
+	grouped := make(map[string][]reflect.Value)
+	for i := 0; i < children.Len(); i++ {
+		child := children.Index(i)
+		value, ok := recordValue(child, matchColumn)
+		if !ok {
+			value, ok = extras[i][matchColumn]
+		}
+		if !ok {
+			continue
+		}
+		if record, isMap := child.Interface().(map[string]any); isMap && matchColumn == pivotKeyAlias {
+			delete(record, pivotKeyAlias)
+		}
+		if key, ok := keyString(value); ok {
+			grouped[key] = append(grouped[key], child)
+		}
+	}
+
+	for i := 0; i < parents.Len(); i++ {
+		parent := parents.Index(i)
+		value, ok := recordValue(parent, relation.parentKey())
+		if !ok {
+			continue
+		}
+		key, _ := keyString(value)
+		if err := setRelation(parent, name, relation, childType, grouped[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func relationElemType(parentType reflect.Type, name string, relation Relation) (reflect.Type, error) {
+	base := indirectType(parentType)
+	if base.Kind() == reflect.Map {
+		return mapType, nil
+	}
+	if base.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("gokit: cannot load relation %q into %s", name, parentType)
+	}
+
+	index, ok := getStructInfo(base).relations[name]
+	if !ok {
+		return nil, fmt.Errorf("gokit: %s has no field for relation %q", base, name)
+	}
+
+	fieldType := base.FieldByIndex(index).Type
+	if relation.many() {
+		if fieldType.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("gokit: field for relation %q on %s must be a slice", name, base)
+		}
+		return fieldType.Elem(), nil
+	}
+	return indirectType(fieldType), nil
+}
+
+func setRelation(parent reflect.Value, name string, relation Relation, childType reflect.Type, children []reflect.Value) error {
+	for parent.Kind() == reflect.Pointer || parent.Kind() == reflect.Interface {
+		if parent.IsNil() {
+			return nil
+		}
+		parent = parent.Elem()
+	}
+
+	if parent.Kind() == reflect.Map {
+		var value any
+		if relation.many() {
+			records := make([]map[string]any, 0, len(children))
+			for _, child := range children {
+				records = append(records, child.Interface().(map[string]any))
+			}
+			value = records
+		} else if len(children) > 0 {
+			value = children[0].Interface()
+		}
+		parent.SetMapIndex(reflect.ValueOf(name), reflect.ValueOf(&value).Elem())
+		return nil
+	}
+
+	field := parent.FieldByIndex(getStructInfo(parent.Type()).relations[name])
+	if relation.many() {
+		slice := reflect.MakeSlice(field.Type(), 0, len(children))
+		slice = reflect.Append(slice, children...)
+		field.Set(slice)
+		return nil
+	}
+
+	if len(children) == 0 {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	field.Set(wrapPointer(children[0], field.Type()))
+	return nil
+}
+
+func (t *TableQueryBuilder) existsClause(table, path string, fn func(QueryBuilder)) (sq.Sqlizer, error) {
+	name, rest, nested := strings.Cut(path, ".")
+	relation, err := t.relations.Get(table, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := t.newQuery(relation.Table)
+	if nested {
+		sub.WhereHas(rest, fn)
+	} else if fn != nil {
+		fn(sub)
+	}
+	if err := sub.prepare(); err != nil {
+		return nil, err
+	}
+
+	var link string
+	if relation.Type == ManyToManyRelation {
+		sub.joins = append(sub.joins, sq.Expr(fmt.Sprintf("JOIN %s ON %s.%s = %s.%s",
+			relation.Pivot, relation.Pivot, relation.RelatedPivotKey, relation.Table, relation.RelatedKey)))
+		link = fmt.Sprintf("%s.%s = %s.%s", relation.Pivot, relation.ForeignPivotKey, table, relation.LocalKey)
+	} else {
+		link = fmt.Sprintf("%s.%s = %s.%s", relation.Table, relation.relatedKey(), table, relation.parentKey())
+	}
+
+	wheres := []whereClause{{boolean: "AND", condition: sq.Expr(link)}}
+	if len(sub.wheres) > 0 {
+		wheres = append(wheres, whereClause{boolean: "AND", condition: whereGroup(sub.wheres)})
+	}
+	sub.columns = []string{"1"}
+	sub.wheres = wheres
+
+	query, args, err := sub.selectBuilder().ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return sq.Expr("EXISTS ("+query+")", args...), nil
+}
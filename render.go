@@ -0,0 +1,133 @@
+package gokit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var defaultEncoders = DefaultEncoders()
+
+var callbackPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$.]*$`)
+
+func (r *router) RegisterEncoder(mediaType string, encoder Encoder) {
+	r.root.encoders.Register(mediaType, encoder)
+}
+
+func (c *Ctx) encoderRegistry() *EncoderRegistry {
+	if c.encoders == nil {
+		return defaultEncoders
+	}
+	return c.encoders
+}
+
+// Negotiate encodes data in the format the Accept header prefers among the
+// registered encoders, or responds 406 when none is acceptable.
+func (c *Ctx) Negotiate(code int, data any) {
+	c.response.Header().Add("Vary", "Accept")
+	mediaType, encoder, ok := c.encoderRegistry().Negotiate(c.request.Header.Get("Accept"))
+	if !ok {
+		c.Error(NewHTTPError(http.StatusNotAcceptable, "not_acceptable", "None of the acceptable media types can be produced."))
+		return
+	}
+	c.Render(code, mediaType, encoder, data)
+}
+
+// Render encodes data before writing anything, so an encoding error still
+// reaches the error handler.
+func (c *Ctx) Render(code int, contentType string, encoder Encoder, data any) {
+	var body bytes.Buffer
+	if err := encoder(&body, data); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if strings.HasPrefix(contentType, "text/") && !strings.Contains(contentType, "charset") {
+		contentType += "; charset=utf-8"
+	}
+	c.response.Header().Set("Content-Type", contentType)
+	c.response.WriteHeader(code)
+	c.response.Write(body.Bytes())
+}
+
+func (c *Ctx) XML(code int, obj any) {
+	c.Render(code, "application/xml", encodeXML, obj)
+}
+
+func (c *Ctx) YAML(code int, obj any) {
+	c.Render(code, "application/yaml", encodeYAML, obj)
+}
+
+// JSONP wraps the JSON body in the function named by the callback query
+// parameter, falling back to plain JSON when there is none.
+func (c *Ctx) JSONP(code int, obj any) {
+	callback := c.Query("callback")
+	if callback == "" {
+		c.JSON(code, obj)
+		return
+	}
+	if !callbackPattern.MatchString(callback) {
+		c.Error(NewHTTPError(http.StatusBadRequest, "invalid_callback", "The callback parameter is not a valid function name."))
+		return
+	}
+
+	c.Render(code, "application/javascript", func(w io.Writer, data any) error {
+		body, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, "/**/ typeof "+callback+" === 'function' && "+callback+"("+string(body)+");")
+		return err
+	}, obj)
+}
+
+func (c *Ctx) HTML(code int, html string) {
+	c.response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.response.WriteHeader(code)
+	io.WriteString(c.response, html)
+}
+
+// File serves path straight to the client; the buffer is committed first so
+// large files are not held in memory.
+func (c *Ctx) File(path string) {
+	c.response.commit()
+	http.ServeFile(c.response, c.request, path)
+}
+
+func (c *Ctx) Download(path, name string) {
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	c.response.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.File(path)
+}
+
+// Stream copies reader to the client, flushing after every chunk so the
+// response is not held in the buffer.
+func (c *Ctx) Stream(code int, contentType string, reader io.Reader) error {
+	c.response.Header().Set("Content-Type", contentType)
+	c.response.WriteHeader(code)
+	c.response.Flush()
+
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := reader.Read(chunk)
+		if n > 0 {
+			if _, writeErr := c.response.Write(chunk[:n]); writeErr != nil {
+				return writeErr
+			}
+			c.response.Flush()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
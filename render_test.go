@@ -0,0 +1,53 @@
+package gokit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type versionDocument struct {
+	Version string `json:"version" xml:"version"`
+}
+
+func TestNegotiateVendorMediaType(t *testing.T) {
+	r := NewRouter()
+	r.SetVersioning(VersionConfig{Vendor: "app"})
+	r.Version("v2", func(v Router) {
+		v.GET("/users", func(ctx Context) {
+			ctx.Negotiate(http.StatusOK, versionDocument{Version: ctx.Version()})
+		})
+	})
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"application/vnd.app.v2+json", "application/vnd.app.v2+json"},
+		{"application/vnd.app.v2+xml, application/json;q=0.5", "application/vnd.app.v2+xml"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Accept %q: status = %d, want %d: %s", tt.accept, w.Code, http.StatusOK, w.Body)
+		}
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+			t.Fatalf("Accept %q: Content-Type = %q, want %q", tt.accept, got, tt.contentType)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Accept", "application/vnd.app.v2+json")
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["version"] != "v2" {
+		t.Fatalf("body = %s, want the v2 JSON document", w.Body)
+	}
+}
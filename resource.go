@@ -0,0 +1,111 @@
+package gokit
+
+import (
+	"net/http"
+	"strings"
+)
+
+type ResourceIndexer interface {
+	Index(ctx Context)
+}
+
+type ResourceCreator interface {
+	Create(ctx Context)
+}
+
+type ResourceStorer interface {
+	Store(ctx Context)
+}
+
+type ResourceShower interface {
+	Show(ctx Context)
+}
+
+type ResourceEditor interface {
+	Edit(ctx Context)
+}
+
+type ResourceUpdater interface {
+	Update(ctx Context)
+}
+
+type ResourceDestroyer interface {
+	Destroy(ctx Context)
+}
+
+// Resource registers the conventional REST routes for every action the
+// controller implements. A path such as "/users/{user}/posts" nests the
+// resource under its parent: routes are named "users.posts.<action>" and
+// the member parameter is {post}.
+func (r *router) Resource(path string, controller any, middleware ...MiddlewareFunc) {
+	r.resource(path, controller, true, middleware)
+}
+
+func (r *router) APIResource(path string, controller any, middleware ...MiddlewareFunc) {
+	r.resource(path, controller, false, middleware)
+}
+
+func (r *router) resource(path string, controller any, forms bool, middleware []MiddlewareFunc) {
+	path = "/" + strings.Trim(path, "/")
+	name := resourceName(r.prefix + path)
+	if r.version != "" && !r.root.versioning.Prefix {
+		name = r.version + "." + name
+	}
+	member := path + "/{" + resourceParam(path) + "}"
+
+	register := func(method, routePath, action string, handler HandlerFunc) {
+		r.handle(method, routePath, handler, middleware).Name(name + "." + action)
+	}
+
+	if c, ok := controller.(ResourceIndexer); ok {
+		register(http.MethodGet, path, "index", c.Index)
+	}
+	if c, ok := controller.(ResourceCreator); ok && forms {
+		register(http.MethodGet, path+"/create", "create", c.Create)
+	}
+	if c, ok := controller.(ResourceStorer); ok {
+		register(http.MethodPost, path, "store", c.Store)
+	}
+	if c, ok := controller.(ResourceShower); ok {
+		register(http.MethodGet, member, "show", c.Show)
+	}
+	if c, ok := controller.(ResourceEditor); ok && forms {
+		register(http.MethodGet, member+"/edit", "edit", c.Edit)
+	}
+	if c, ok := controller.(ResourceUpdater); ok {
+		register(http.MethodPut, member, "update", c.Update)
+		r.handle(http.MethodPatch, member, c.Update, middleware)
+	}
+	if c, ok := controller.(ResourceDestroyer); ok {
+		register(http.MethodDelete, member, "destroy", c.Destroy)
+	}
+}
+
+func resourceName(path string) string {
+	parts := make([]string, 0)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || strings.HasPrefix(segment, "{") {
+			continue
+		}
+		parts = append(parts, segment)
+	}
+	return strings.Join(parts, ".")
+}
+
+func resourceParam(path string) string {
+	return singular(path[strings.LastIndex(path, "/")+1:])
+}
+
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
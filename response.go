@@ -0,0 +1,141 @@
+package gokit
+
+import (
+	"bufio"
+	"bytes"
+	"maps"
+	"net"
+	"net/http"
+)
+
+// responseWriter records the status and size of a response. When buffered,
+// the body is held until the request finishes or the handler flushes, so
+// middleware can inspect it and the error handler can replace a partial
+// response.
+type responseWriter struct {
+	http.ResponseWriter
+	buffered    bool
+	status      int
+	size        int
+	wroteHeader bool
+	committed   bool
+	buffer      bytes.Buffer
+	snapshot    http.Header
+}
+
+func newResponseWriter(w http.ResponseWriter, buffered bool) *responseWriter {
+	return &responseWriter{ResponseWriter: w, buffered: buffered, status: http.StatusOK}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = code
+	w.wroteHeader = true
+	if w.committed {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if !w.buffered {
+		w.commit()
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.size += len(b)
+	if w.committed {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buffer.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	w.commit()
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.committed = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// commit sends the status and anything buffered to the client; later writes
+// go straight through.
+func (w *responseWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buffer.Len() > 0 {
+		w.ResponseWriter.Write(w.buffer.Bytes())
+		w.buffer.Reset()
+	}
+}
+
+// mark records the headers set so far, so reset can drop the ones the
+// handler adds after it.
+func (w *responseWriter) mark() {
+	w.snapshot = w.Header().Clone()
+}
+
+// reset discards an uncommitted response so it can be rewritten, restoring
+// the headers recorded by mark.
+func (w *responseWriter) reset() bool {
+	if w.committed {
+		return false
+	}
+	w.buffer.Reset()
+	w.status = http.StatusOK
+	w.size = 0
+	w.wroteHeader = false
+
+	header := w.Header()
+	if w.snapshot == nil {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		return true
+	}
+	clear(header)
+	maps.Copy(header, w.snapshot.Clone())
+	return true
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+// Body returns the buffered body, or nil once the response has been
+// committed.
+func (w *responseWriter) Body() []byte {
+	if w.committed {
+		return nil
+	}
+	return w.buffer.Bytes()
+}
+
+func (w *responseWriter) Headers() map[string]string {
+	headers := make(map[string]string, len(w.Header()))
+	for key := range w.Header() {
+		headers[key] = w.Header().Get(key)
+	}
+	return headers
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.wroteHeader
+}
@@ -3,112 +3,324 @@ package gokit
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
 )
 
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+var probeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
 type router struct {
-	mux        *http.ServeMux
-	routes     []Route
-	middleware []MiddlewareFunc
-	prefix     string
+	mux           *http.ServeMux
+	root          *router
+	parent        *router
+	routes        []*Route
+	named         map[string]*Route
+	bindings      map[string]binding
+	db            Database
+	validator     Validator
+	errs          []error
+	dispatchers   map[string]*dispatcher
+	signingKey    []byte
+	middleware    []MiddlewareFunc
+	prefix        string
+	host          string
+	version       string
+	versioning    VersionConfig
+	vendorPattern *regexp.Regexp
+	deprecations  map[string]deprecation
+	encoders      *EncoderRegistry
+	errorHandler  ErrorHandlerFunc
+	notFound      HandlerFunc
+	notAllowed    HandlerFunc
+	serverConfig  ServerConfig
+	server        *http.Server
+	shutdownHooks []func()
+	mu            sync.Mutex
 }
 
 type Route struct {
-	method  string
-	path    string
-	handler HandlerFunc
+	method          string
+	path            string
+	handler         HandlerFunc
+	middleware      []MiddlewareFunc
+	group           *router
+	name            string
+	constraints     map[string]*regexp.Regexp
+	params          []string
+	keys            map[string]string
+	host            string
+	hostPattern     *regexp.Regexp
+	hostParams      []string
+	hostConstraints map[string]*regexp.Regexp
+	version         string
 }
 
 func NewRouter() Router {
-	return &router{
-		mux:        http.NewServeMux(),
-		routes:     make([]Route, 0),
-		middleware: make([]MiddlewareFunc, 0),
-		prefix:     "",
+	return NewRouterWithConfig(nil)
+}
+
+func NewRouterWithConfig(config Config) Router {
+	r := &router{
+		mux:          http.NewServeMux(),
+		routes:       make([]*Route, 0),
+		named:        make(map[string]*Route),
+		bindings:     make(map[string]binding),
+		dispatchers:  make(map[string]*dispatcher),
+		versioning:   DefaultVersionConfig(),
+		deprecations: make(map[string]deprecation),
+		encoders:     DefaultEncoders(),
+		validator:    NewValidator(),
+		middleware:   make([]MiddlewareFunc, 0),
+		prefix:       "",
+		errorHandler: DefaultErrorHandler,
+		notFound:     defaultNotFound,
+		notAllowed:   defaultMethodNotAllowed,
+		serverConfig: ServerConfigFrom(config),
 	}
+	r.root = r
+	return r
 }
 
-func (r *router) DELETE(path string, handler HandlerFunc) {
-	fullPath := r.prefix + path
-	r.routes = append(r.routes, Route{method: "DELETE", path: fullPath, handler: handler})
-	r.mux.HandleFunc("DELETE "+fullPath, func(w http.ResponseWriter, req *http.Request) {
-		ctx := NewCtx(w, req)
-		r.applyMiddleware(ctx)
-		handler(ctx)
-	})
+func (r *router) DELETE(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle("DELETE", path, handler, middleware)
 }
 
-func (r *router) GET(path string, handler HandlerFunc) {
-	fullPath := r.prefix + path
-	r.routes = append(r.routes, Route{method: "GET", path: fullPath, handler: handler})
-	r.mux.HandleFunc("GET "+fullPath, func(w http.ResponseWriter, req *http.Request) {
-		ctx := NewCtx(w, req)
-		r.applyMiddleware(ctx)
-		handler(ctx)
-	})
+func (r *router) GET(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle("GET", path, handler, middleware)
 }
 
-func (r *router) Group(prefix string, fn func(Router)) {
-	groupRouter := &router{
-		mux:        r.mux,
-		routes:     make([]Route, 0),
-		middleware: make([]MiddlewareFunc, len(r.middleware)),
-		prefix:     r.prefix + prefix,
-	}
-	copy(groupRouter.middleware, r.middleware)
+// Group does not copy the parent's middleware: it is resolved on each request,
+// so middleware added to a parent after the group was created still applies.
+func (r *router) Group(prefix string, fn func(Router), middleware ...MiddlewareFunc) {
+	groupRouter := r.subrouter(r.prefix+prefix, r.host, r.version, middleware)
 	fn(groupRouter)
 	r.routes = append(r.routes, groupRouter.routes...)
 }
 
-func (r *router) Listen(addr string) error {
-	fmt.Printf("🚀 Server starting on %s\n", addr)
-	return http.ListenAndServe(addr, r.mux)
+// subrouter returns a child of r that shares its mux and root; Group, Domain
+// and Version differ only in the prefix, host and version they pass.
+func (r *router) subrouter(prefix, host, version string, middleware []MiddlewareFunc) *router {
+	return &router{
+		mux:        r.mux,
+		root:       r.root,
+		parent:     r,
+		routes:     make([]*Route, 0),
+		middleware: append([]MiddlewareFunc{}, middleware...),
+		prefix:     prefix,
+		host:       host,
+		version:    version,
+	}
+}
+
+func (r *router) HEAD(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle("HEAD", path, handler, middleware)
 }
 
-func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	r.mux.ServeHTTP(w, req)
+func (r *router) OPTIONS(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle("OPTIONS", path, handler, middleware)
+}
+
+func (r *router) Any(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.Match(anyMethods, path, handler, middleware...)
 }
 
-func (r *router) applyMiddleware(ctx Context) {
-	for _, mw := range r.middleware {
-		mw(ctx)
+// Match registers handler for each method and returns the first route, which
+// is the one Name attaches to.
+func (r *router) Match(methods []string, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	var first *Route
+	for _, method := range methods {
+		route := r.handle(strings.ToUpper(method), path, handler, middleware)
+		if first == nil {
+			first = route
+		}
 	}
+	return first
 }
 
-func (r *router) PATCH(path string, handler HandlerFunc) {
-	fullPath := r.prefix + path
-	r.routes = append(r.routes, Route{method: "PATCH", path: fullPath, handler: handler})
-	r.mux.HandleFunc("PATCH "+fullPath, func(w http.ResponseWriter, req *http.Request) {
-		ctx := NewCtx(w, req)
-		r.applyMiddleware(ctx)
+func (r *router) NotFound(handler HandlerFunc) {
+	r.root.notFound = handler
+}
+
+func (r *router) MethodNotAllowed(handler HandlerFunc) {
+	r.root.notAllowed = handler
+}
+
+// ServeHTTP answers requests the mux has no route for itself, so 404s, 405s
+// and automatic OPTIONS responses go through the router's middleware and
+// error format. GET routes already answer HEAD through the mux.
+func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	root := r.root
+	if _, pattern := root.mux.Handler(req); pattern != "" {
+		root.mux.ServeHTTP(w, req)
+		return
+	}
+	if fallback := root.defaultVersionRequest(req); fallback != nil {
+		root.mux.ServeHTTP(w, fallback)
+		return
+	}
+
+	handler := root.notFound
+	if allowed := root.allowedMethods(req); len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if req.Method == http.MethodOptions {
+			handler = func(ctx Context) {
+				ctx.Writer().WriteHeader(http.StatusNoContent)
+			}
+		} else {
+			handler = root.notAllowed
+		}
+	}
+
+	root.run(w, req, "", root.middleware, func(ctx *Ctx) {
 		handler(ctx)
 	})
 }
 
-func (r *router) POST(path string, handler HandlerFunc) {
-	fullPath := r.prefix + path
-	r.routes = append(r.routes, Route{method: "POST", path: fullPath, handler: handler})
-	r.mux.HandleFunc("POST "+fullPath, func(w http.ResponseWriter, req *http.Request) {
-		ctx := NewCtx(w, req)
-		r.applyMiddleware(ctx)
-		handler(ctx)
+func (r *router) allowedMethods(req *http.Request) []string {
+	allowed := make([]string, 0)
+	for _, method := range probeMethods {
+		probe := *req
+		probe.Method = method
+		if _, pattern := r.mux.Handler(&probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) > 0 && !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return allowed
+}
+
+func defaultNotFound(ctx Context) {
+	ctx.Error(NewHTTPError(http.StatusNotFound, "not_found", "The requested resource was not found."))
+}
+
+func defaultMethodNotAllowed(ctx Context) {
+	ctx.Error(NewHTTPError(http.StatusMethodNotAllowed, "method_not_allowed", "The request method is not supported for this resource."))
+}
+
+func (r *router) handle(method, path string, handler HandlerFunc, middleware []MiddlewareFunc) *Route {
+	pattern, err := parsePattern(r.prefix + path)
+	route := &Route{
+		method:      method,
+		path:        pattern.path,
+		handler:     handler,
+		middleware:  append([]MiddlewareFunc{}, middleware...),
+		group:       r,
+		version:     r.version,
+		constraints: pattern.constraints,
+		params:      pattern.params,
+		keys:        pattern.keys,
+	}
+	if err == nil {
+		err = route.setHost(r.host)
+	}
+	if err != nil {
+		r.root.errs = append(r.root.errs, fmt.Errorf("gokit: route %s %s%s: %w", method, r.host, r.prefix+path, err))
+		return route
+	}
+
+	if err := r.register(route); err != nil {
+		r.root.errs = append(r.root.errs, err)
+		return route
+	}
+	r.routes = append(r.routes, route)
+	return route
+}
+
+func (route *Route) serve(w http.ResponseWriter, req *http.Request) {
+	root := route.group.root
+	version := route.servedVersion(req)
+	if !route.matches(req) {
+		root.run(w, req, version, root.middleware, func(ctx *Ctx) {
+			root.notFound(ctx)
+		})
+		return
+	}
+
+	root.markDeprecated(w, route.version)
+	root.run(w, req, version, route.Middleware(), func(ctx *Ctx) {
+		if err := route.resolveBindings(ctx); err != nil {
+			ctx.Error(err)
+			return
+		}
+		route.handler(ctx)
 	})
 }
 
-func (r *router) PUT(path string, handler HandlerFunc) {
-	fullPath := r.prefix + path
-	r.routes = append(r.routes, Route{method: "PUT", path: fullPath, handler: handler})
-	r.mux.HandleFunc("PUT "+fullPath, func(w http.ResponseWriter, req *http.Request) {
-		ctx := NewCtx(w, req)
-		r.applyMiddleware(ctx)
+// run executes handler behind middleware with a buffered response, which is
+// committed once the chain returns.
+func (r *router) run(w http.ResponseWriter, req *http.Request, version string, middleware []MiddlewareFunc, handler func(*Ctx)) {
+	ctx := &Ctx{
+		request:      req,
+		response:     newResponseWriter(w, true),
+		errorHandler: r.root.handleError,
+		version:      version,
+		encoders:     r.root.encoders,
+		validator:    r.root.validator,
+	}
+	chain(middleware, func(Context) {
+		ctx.response.mark()
 		handler(ctx)
-	})
+	})(ctx)
+	ctx.response.commit()
+}
+
+func (r *router) SetErrorHandler(handler ErrorHandlerFunc) {
+	r.root.errorHandler = handler
+}
+
+func (r *router) handleError(ctx Context, err error) {
+	r.root.errorHandler(ctx, err)
+}
+
+// stack returns the middleware of every router from the root down to r.
+func (r *router) stack() []MiddlewareFunc {
+	if r.parent == nil {
+		return append([]MiddlewareFunc{}, r.middleware...)
+	}
+	return append(r.parent.stack(), r.middleware...)
+}
+
+// chain wraps handler in middleware, outermost first, so each middleware
+// decides whether and when the rest of the chain runs.
+func chain(middleware []MiddlewareFunc, handler HandlerFunc) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw, next := middleware[i], handler
+		handler = func(ctx Context) {
+			mw(ctx, func() { next(ctx) })
+		}
+	}
+	return handler
+}
+
+func (r *router) PATCH(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle("PATCH", path, handler, middleware)
+}
+
+func (r *router) POST(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle("POST", path, handler, middleware)
+}
+
+func (r *router) PUT(path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return r.handle("PUT", path, handler, middleware)
 }
 
 func (r *router) Use(middleware ...MiddlewareFunc) {
 	r.middleware = append(r.middleware, middleware...)
 }
 
-func (r *router) Routes() []Route {
+func (r *router) Routes() []*Route {
 	return r.routes
 }
 
@@ -120,6 +332,50 @@ func (route *Route) Path() string {
 	return route.path
 }
 
+func (route *Route) Host() string {
+	return route.host
+}
+
+func (route *Route) Name(name string) *Route {
+	root := route.group.root
+	if existing, exists := root.named[name]; exists && existing != route {
+		root.errs = append(root.errs, fmt.Errorf("%w: name %q is used by %s %s and %s %s",
+			ErrRouteConflict, name, existing.method, existing.path, route.method, route.path))
+		return route
+	}
+	route.name = name
+	root.named[name] = route
+	return route
+}
+
+func (route *Route) RouteName() string {
+	return route.name
+}
+
 func (route *Route) Handler() HandlerFunc {
 	return route.handler
 }
+
+// Middleware returns the effective stack for the route: router, group and
+// route middleware, outermost first.
+func (route *Route) Middleware() []MiddlewareFunc {
+	return append(route.group.stack(), route.middleware...)
+}
+
+func (route *Route) MiddlewareNames() []string {
+	middleware := route.Middleware()
+	names := make([]string, 0, len(middleware))
+	for _, mw := range middleware {
+		names = append(names, funcName(mw))
+	}
+	return names
+}
+
+func funcName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
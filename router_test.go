@@ -0,0 +1,88 @@
+package gokit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func serve(t *testing.T, r Router, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestMiddlewareAbort(t *testing.T) {
+	r := NewRouter()
+	handlerRan := false
+	r.GET("/secret", func(ctx Context) {
+		handlerRan = true
+		ctx.String(http.StatusOK, "secret")
+	}, func(ctx Context, next Next) {
+		ctx.Error(NewHTTPError(http.StatusUnauthorized, "unauthorized", "Authentication required."))
+	})
+
+	w := serve(t, r, http.MethodGet, "/secret")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if handlerRan {
+		t.Fatal("handler ran after middleware aborted")
+	}
+}
+
+func TestMiddlewarePostProcessing(t *testing.T) {
+	r := NewRouter()
+	var afterStatus int
+	r.Use(func(ctx Context, next Next) {
+		next()
+		afterStatus = ctx.Response().Status()
+		ctx.SetHeader("X-After", "done")
+	})
+	r.GET("/created", func(ctx Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	w := serve(t, r, http.MethodGet, "/created")
+	if afterStatus != http.StatusCreated {
+		t.Fatalf("status seen after next = %d, want %d", afterStatus, http.StatusCreated)
+	}
+	if got := w.Header().Get("X-After"); got != "done" {
+		t.Fatalf("X-After = %q, want %q", got, "done")
+	}
+}
+
+func TestMiddlewareOrderAcrossGroups(t *testing.T) {
+	r := NewRouter()
+	var calls []string
+	record := func(name string) MiddlewareFunc {
+		return func(ctx Context, next Next) {
+			calls = append(calls, name+" before")
+			next()
+			calls = append(calls, name+" after")
+		}
+	}
+
+	r.Use(record("global"))
+	r.Group("/api", func(api Router) {
+		api.Use(record("api"))
+		api.Group("/admin", func(admin Router) {
+			admin.GET("/users", func(ctx Context) {
+				calls = append(calls, "handler")
+				ctx.NoContent()
+			}, record("route"))
+		}, record("admin"))
+	})
+
+	serve(t, r, http.MethodGet, "/api/admin/users")
+	want := []string{
+		"global before", "api before", "admin before", "route before",
+		"handler",
+		"route after", "admin after", "api after", "global after",
+	}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
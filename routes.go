@@ -0,0 +1,70 @@
+package gokit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var ErrRouteConflict = errors.New("gokit: route conflict")
+
+var registeredAtPattern = regexp.MustCompile(` \(registered at [^)]*\)`)
+
+// register adds the route to the mux. Routes that share a mux pattern but
+// differ by wildcard host share one dispatcher; the mux's panic on ambiguous
+// patterns is turned into an error that Validate reports.
+func (r *router) register(route *Route) (err error) {
+	pattern := route.muxPattern()
+	if existing, exists := r.root.dispatchers[pattern]; exists {
+		return existing.add(route)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			message := registeredAtPattern.ReplaceAllString(fmt.Sprint(recovered), "")
+			err = fmt.Errorf("%w: %s", ErrRouteConflict, message)
+		}
+	}()
+
+	d := &dispatcher{}
+	d.add(route)
+	r.mux.Handle(pattern, d)
+	r.root.dispatchers[pattern] = d
+	return nil
+}
+
+// Validate reports every route that could not be registered. Listen calls it
+// before serving, so a bad route table fails at startup.
+func (r *router) Validate() error {
+	return errors.Join(r.root.errs...)
+}
+
+func (route *Route) HandlerName() string {
+	return funcName(route.handler)
+}
+
+func PrintRoutes(w io.Writer, routes []*Route) {
+	sorted := append([]*Route{}, routes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].host+sorted[i].path < sorted[j].host+sorted[j].path
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHANDLER\tMIDDLEWARE")
+	for _, route := range sorted {
+		name := route.name
+		if name == "" {
+			name = "-"
+		}
+		middleware := strings.Join(route.MiddlewareNames(), ", ")
+		if middleware == "" {
+			middleware = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", route.method, route.host+route.path, name, route.HandlerName(), middleware)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,427 @@
+package gokit
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+type structInfo struct {
+	columns   map[string][]int
+	order     []string
+	relations map[string][]int
+}
+
+var structInfoCache sync.Map
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	mapType     = reflect.TypeOf(map[string]any{})
+)
+
+func getStructInfo(t reflect.Type) *structInfo {
+	if cached, ok := structInfoCache.Load(t); ok {
+		return cached.(*structInfo)
+	}
+
+	info := &structInfo{
+		columns:   make(map[string][]int),
+		order:     make([]string, 0),
+		relations: make(map[string][]int),
+	}
+	collectFields(t, nil, info)
+
+	actual, _ := structInfoCache.LoadOrStore(t, info)
+	return actual.(*structInfo)
+}
+
+func collectFields(t reflect.Type, parent []int, info *structInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		index := append(append([]int{}, parent...), i)
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+			collectFields(field.Type, index, info)
+			continue
+		}
+
+		if name := field.Tag.Get("rel"); name != "" {
+			info.relations[name] = index
+			continue
+		}
+
+		if tag == "" && !isScalarType(field.Type) {
+			info.relations[toSnakeCase(field.Name)] = index
+			continue
+		}
+
+		column := strings.Split(tag, ",")[0]
+		if column == "" {
+			column = toSnakeCase(field.Name)
+		}
+		if _, exists := info.columns[column]; exists {
+			continue
+		}
+		info.columns[column] = index
+		info.order = append(info.order, column)
+	}
+}
+
+func isScalarType(t reflect.Type) bool {
+	if t.Implements(valuerType) || reflect.PointerTo(t).Implements(scannerType) {
+		return true
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return t == timeType || t.Implements(valuerType) || reflect.PointerTo(t).Implements(scannerType)
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	case reflect.Map, reflect.Func, reflect.Chan, reflect.Interface:
+		return false
+	}
+	return true
+}
+
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func toCamelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if strings.EqualFold(part, "id") {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func scanRows(rows *sql.Rows, elemType reflect.Type) (reflect.Value, []map[string]any, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+
+	result := reflect.MakeSlice(reflect.SliceOf(elemType), 0, 0)
+	extras := make([]map[string]any, 0)
+
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return reflect.Value{}, nil, err
+		}
+
+		record, extra, err := buildRecord(elemType, columns, values)
+		if err != nil {
+			return reflect.Value{}, nil, err
+		}
+		result = reflect.Append(result, record)
+		extras = append(extras, extra)
+	}
+
+	return result, extras, rows.Err()
+}
+
+func buildRecord(elemType reflect.Type, columns []string, values []any) (reflect.Value, map[string]any, error) {
+	base := indirectType(elemType)
+	extra := make(map[string]any)
+
+	switch base.Kind() {
+	case reflect.Map:
+		record := make(map[string]any, len(columns))
+		for i, column := range columns {
+			record[column] = normalizeValue(values[i])
+		}
+		return reflect.ValueOf(record), extra, nil
+
+	case reflect.Struct:
+		if isScalarType(base) {
+			break
+		}
+		info := getStructInfo(base)
+		record := reflect.New(base).Elem()
+		for i, column := range columns {
+			index, ok := info.columns[column]
+			if !ok {
+				extra[column] = normalizeValue(values[i])
+				continue
+			}
+			if err := assignValue(record.FieldByIndex(index), values[i]); err != nil {
+				return reflect.Value{}, nil, fmt.Errorf("gokit: column %q: %w", column, err)
+			}
+		}
+		return wrapPointer(record, elemType), extra, nil
+	}
+
+	if len(values) == 0 {
+		return reflect.Value{}, nil, fmt.Errorf("gokit: no columns to scan into %s", elemType)
+	}
+	record := reflect.New(base).Elem()
+	if err := assignValue(record, values[0]); err != nil {
+		return reflect.Value{}, nil, err
+	}
+	return wrapPointer(record, elemType), extra, nil
+}
+
+func wrapPointer(value reflect.Value, target reflect.Type) reflect.Value {
+	for value.Type() != target {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	return value
+}
+
+func normalizeValue(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+func assignValue(field reflect.Value, src any) error {
+	if src == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.CanAddr() {
+		if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(src)
+		}
+	}
+
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := assignValue(elem.Elem(), src); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	srcValue := reflect.ValueOf(src)
+	if srcValue.Type().AssignableTo(field.Type()) {
+		field.Set(srcValue)
+		return nil
+	}
+
+	text, isText := src.(string)
+	if b, ok := src.([]byte); ok {
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes(append([]byte{}, b...))
+			return nil
+		}
+		text, isText = string(b), true
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if isText {
+			field.SetString(text)
+		} else {
+			field.SetString(fmt.Sprint(src))
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isText {
+			n, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetInt(n)
+			return nil
+		}
+		if srcValue.CanInt() {
+			field.SetInt(srcValue.Int())
+			return nil
+		}
+		if srcValue.CanFloat() {
+			field.SetInt(int64(srcValue.Float()))
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if isText {
+			n, err := strconv.ParseUint(text, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetUint(n)
+			return nil
+		}
+		if srcValue.CanInt() {
+			field.SetUint(uint64(srcValue.Int()))
+			return nil
+		}
+		if srcValue.CanUint() {
+			field.SetUint(srcValue.Uint())
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if isText {
+			f, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return err
+			}
+			field.SetFloat(f)
+			return nil
+		}
+		if srcValue.CanFloat() {
+			field.SetFloat(srcValue.Float())
+			return nil
+		}
+		if srcValue.CanInt() {
+			field.SetFloat(float64(srcValue.Int()))
+			return nil
+		}
+
+	case reflect.Bool:
+		if isText {
+			b, err := strconv.ParseBool(text)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+			return nil
+		}
+		if srcValue.CanInt() {
+			field.SetBool(srcValue.Int() != 0)
+			return nil
+		}
+
+	case reflect.Struct:
+		if field.Type() == timeType && isText {
+			t, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+
+	if srcValue.Type().ConvertibleTo(field.Type()) {
+		field.Set(srcValue.Convert(field.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("cannot assign %T to %s", src, field.Type())
+}
+
+func recordValue(record reflect.Value, column string) (any, bool) {
+	for record.Kind() == reflect.Pointer || record.Kind() == reflect.Interface {
+		if record.IsNil() {
+			return nil, false
+		}
+		record = record.Elem()
+	}
+
+	switch record.Kind() {
+	case reflect.Map:
+		value := record.MapIndex(reflect.ValueOf(column))
+		if !value.IsValid() {
+			return nil, false
+		}
+		return value.Interface(), true
+	case reflect.Struct:
+		index, ok := getStructInfo(record.Type()).columns[column]
+		if !ok {
+			return nil, false
+		}
+		return record.FieldByIndex(index).Interface(), true
+	}
+	return nil, false
+}
+
+func keyString(value any) (string, bool) {
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", false
+	}
+
+	if b, ok := v.Interface().([]byte); ok {
+		return string(b), true
+	}
+	return fmt.Sprint(v.Interface()), true
+}
+
+func structValues(value reflect.Value, skipZero bool) ([]string, []any) {
+	info := getStructInfo(value.Type())
+	columns := make([]string, 0, len(info.order))
+	values := make([]any, 0, len(info.order))
+
+	for _, column := range info.order {
+		field := value.FieldByIndex(info.columns[column])
+		if skipZero && field.IsZero() {
+			continue
+		}
+		columns = append(columns, column)
+		values = append(values, field.Interface())
+	}
+	return columns, values
+}
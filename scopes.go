@@ -0,0 +1,53 @@
+package gokit
+
+import (
+	"context"
+	"sync"
+)
+
+// GlobalScope adds conditions to every query on its table. Builders apply
+// scopes once, right before the query runs, and group the caller's own
+// conditions first so an OrWhere cannot escape a scope.
+type GlobalScope func(ctx context.Context, query QueryBuilder) error
+
+type namedScope struct {
+	name  string
+	scope GlobalScope
+}
+
+type ScopeRegistry struct {
+	scopes map[string][]namedScope
+	mu     sync.Mutex
+}
+
+func NewScopeRegistry() *ScopeRegistry {
+	return &ScopeRegistry{
+		scopes: make(map[string][]namedScope),
+	}
+}
+
+func (r *ScopeRegistry) Add(table, name string, scope GlobalScope) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.scopes[table] {
+		if existing.name == name {
+			r.scopes[table][i].scope = scope
+			return
+		}
+	}
+	r.scopes[table] = append(r.scopes[table], namedScope{name: name, scope: scope})
+}
+
+func (r *ScopeRegistry) For(table string, excluded map[string]bool) []GlobalScope {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scopes := make([]GlobalScope, 0, len(r.scopes[table]))
+	for _, scope := range r.scopes[table] {
+		if !excluded[scope.name] {
+			scopes = append(scopes, scope.scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,179 @@
+package gokit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type ServerConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	ShutdownTimeout   time.Duration
+	TLSCert           string
+	TLSKey            string
+	TLSMinVersion     string
+	TLSClientCA       string
+	H2C               bool
+}
+
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		ReadTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+		ShutdownTimeout:   30 * time.Second,
+		TLSMinVersion:     "1.2",
+	}
+}
+
+func ServerConfigFrom(config Config) ServerConfig {
+	server := DefaultServerConfig()
+	if config == nil {
+		return server
+	}
+
+	if d := config.GetDuration("HTTP_READ_TIMEOUT"); d > 0 {
+		server.ReadTimeout = d
+	}
+	if d := config.GetDuration("HTTP_READ_HEADER_TIMEOUT"); d > 0 {
+		server.ReadHeaderTimeout = d
+	}
+	if d := config.GetDuration("HTTP_WRITE_TIMEOUT"); d > 0 {
+		server.WriteTimeout = d
+	}
+	if d := config.GetDuration("HTTP_IDLE_TIMEOUT"); d > 0 {
+		server.IdleTimeout = d
+	}
+	if n := config.GetInt("HTTP_MAX_HEADER_BYTES"); n > 0 {
+		server.MaxHeaderBytes = n
+	}
+	if d := config.GetDuration("HTTP_SHUTDOWN_TIMEOUT"); d > 0 {
+		server.ShutdownTimeout = d
+	}
+
+	server.TLSCert = config.Get("HTTP_TLS_CERT")
+	server.TLSKey = config.Get("HTTP_TLS_KEY")
+	server.TLSMinVersion = config.GetWithDefault("HTTP_TLS_MIN_VERSION", server.TLSMinVersion)
+	server.TLSClientCA = config.Get("HTTP_TLS_CLIENT_CA")
+	server.H2C = config.GetBool("HTTP_H2C")
+	return server
+}
+
+func (r *router) Listen(addr string) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	config := r.root.serverConfig
+	if config.TLSCert != "" && config.TLSKey != "" {
+		return r.ListenTLS(addr, config.TLSCert, config.TLSKey)
+	}
+
+	server := r.newServer(addr)
+	if config.H2C {
+		server.Protocols = new(http.Protocols)
+		server.Protocols.SetHTTP1(true)
+		server.Protocols.SetUnencryptedHTTP2(true)
+	}
+
+	fmt.Printf("🚀 Server starting on %s\n", addr)
+	return r.serve(server, server.ListenAndServe)
+}
+
+func (r *router) ListenTLS(addr, certFile, keyFile string) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	tlsConfig, err := r.tlsConfig(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	server := r.newServer(addr)
+	server.TLSConfig = tlsConfig
+
+	fmt.Printf("🔒 Server starting on %s (TLS)\n", addr)
+	return r.serve(server, func() error {
+		return server.ListenAndServeTLS("", "")
+	})
+}
+
+func (r *router) Shutdown(ctx context.Context) error {
+	root := r.root
+	root.mu.Lock()
+	server := root.server
+	hooks := root.shutdownHooks
+	root.server = nil
+	root.mu.Unlock()
+
+	var err error
+	if server != nil {
+		err = server.Shutdown(ctx)
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+	return err
+}
+
+func (r *router) OnShutdown(fn func()) {
+	r.root.mu.Lock()
+	defer r.root.mu.Unlock()
+	r.root.shutdownHooks = append(r.root.shutdownHooks, fn)
+}
+
+func (r *router) newServer(addr string) *http.Server {
+	config := r.root.serverConfig
+	return &http.Server{
+		Addr:              addr,
+		Handler:           r.root,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+}
+
+// serve runs start until it fails, Shutdown is called, or the process
+// receives SIGINT/SIGTERM, in which case in-flight requests are drained.
+func (r *router) serve(server *http.Server, start func() error) error {
+	r.root.mu.Lock()
+	r.root.server = server
+	r.root.mu.Unlock()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- start()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case sig := <-signals:
+		fmt.Printf("🛑 Received %s, shutting down\n", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.root.serverConfig.ShutdownTimeout)
+	defer cancel()
+	return r.Shutdown(ctx)
+}
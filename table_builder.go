@@ -0,0 +1,884 @@
+package gokit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRecordNotFound      = gorm.ErrRecordNotFound
+	ErrMissingWhereClause  = gorm.ErrMissingWhereClause
+	ErrNotInTransaction    = errors.New("gokit: query builder is not in a transaction")
+	ErrUnsupportedArgument = errors.New("gokit: unsupported argument type")
+)
+
+type queryRunner interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type TableQueryBuilder struct {
+	runner      queryRunner
+	ctx         context.Context
+	relations   *RelationRegistry
+	scopes      *ScopeRegistry
+	excluded    map[string]bool
+	scoped      bool
+	placeholder sq.PlaceholderFormat
+	table       string
+	from        sq.Sqlizer
+	ctes        []commonTable
+	unions      []union
+	columns     []string
+	joins       []sq.Sqlizer
+	wheres      []whereClause
+	orderBys    []string
+	groupBys    []string
+	havings     []sq.Sqlizer
+	limit       int
+	offset      int
+	eager       []string
+	err         error
+}
+
+func NewTableQueryBuilder(runner queryRunner, table string, relations *RelationRegistry, scopes *ScopeRegistry) QueryBuilder {
+	return newTableQueryBuilder(runner, table, relations, scopes)
+}
+
+func newTableQueryBuilder(runner queryRunner, table string, relations *RelationRegistry, scopes *ScopeRegistry) *TableQueryBuilder {
+	if relations == nil {
+		relations = NewRelationRegistry()
+	}
+	if scopes == nil {
+		scopes = NewScopeRegistry()
+	}
+	return &TableQueryBuilder{
+		runner:      runner,
+		ctx:         context.Background(),
+		relations:   relations,
+		scopes:      scopes,
+		excluded:    make(map[string]bool),
+		placeholder: sq.Dollar,
+		table:       table,
+		limit:       -1,
+		offset:      -1,
+	}
+}
+
+func (t *TableQueryBuilder) newQuery(table string) *TableQueryBuilder {
+	query := newTableQueryBuilder(t.runner, table, t.relations, t.scopes)
+	query.ctx = t.ctx
+	query.placeholder = t.placeholder
+	return query
+}
+
+func (t *TableQueryBuilder) WithContext(ctx context.Context) QueryBuilder {
+	t.ctx = ctx
+	return t
+}
+
+func (t *TableQueryBuilder) WithoutGlobalScope(names ...string) QueryBuilder {
+	for _, name := range names {
+		t.excluded[name] = true
+	}
+	return t
+}
+
+// prepare applies the table's global scopes.
+func (t *TableQueryBuilder) prepare() error {
+	if t.err != nil || t.scoped {
+		return t.err
+	}
+	t.scoped = true
+
+	for _, scope := range t.scopes.For(t.table, t.excluded) {
+		group := t.newQuery(t.table)
+		if err := scope(t.ctx, group); err != nil {
+			t.err = err
+			return err
+		}
+		if group.err != nil {
+			t.err = group.err
+			return group.err
+		}
+		if len(group.wheres) == 0 {
+			continue
+		}
+
+		if len(t.wheres) > 1 {
+			t.wheres = []whereClause{{boolean: "AND", condition: whereGroup(t.wheres)}}
+		}
+		t.wheres = append(t.wheres, whereClause{boolean: "AND", condition: whereGroup(group.wheres)})
+	}
+	return nil
+}
+
+func (t *TableQueryBuilder) Select(columns ...string) QueryBuilder {
+	t.columns = append(t.columns, columns...)
+	return t
+}
+
+func (t *TableQueryBuilder) Where(query any, args ...any) QueryBuilder {
+	return t.addWhere("AND", query, args, false)
+}
+
+func (t *TableQueryBuilder) OrWhere(query any, args ...any) QueryBuilder {
+	return t.addWhere("OR", query, args, false)
+}
+
+func (t *TableQueryBuilder) WhereNot(query any, args ...any) QueryBuilder {
+	return t.addWhere("AND", query, args, true)
+}
+
+func (t *TableQueryBuilder) WhereIn(column string, values any) QueryBuilder {
+	builder, ok := values.(QueryBuilder)
+	if !ok {
+		return t.addColumnWhere(column, func(column string) sq.Sqlizer {
+			return sq.Eq{column: values}
+		})
+	}
+
+	sub, err := toSubquery(builder)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	return t.addColumnWhere(column, func(column string) sq.Sqlizer {
+		return sq.Expr(column+" IN (?)", sub)
+	})
+}
+
+func (t *TableQueryBuilder) WhereNull(column string) QueryBuilder {
+	return t.addColumnWhere(column, func(column string) sq.Sqlizer {
+		return sq.Eq{column: nil}
+	})
+}
+
+func (t *TableQueryBuilder) WhereNotNull(column string) QueryBuilder {
+	return t.addColumnWhere(column, func(column string) sq.Sqlizer {
+		return sq.NotEq{column: nil}
+	})
+}
+
+func (t *TableQueryBuilder) WhereBetween(column string, from, to any) QueryBuilder {
+	return t.addColumnWhere(column, func(column string) sq.Sqlizer {
+		return sq.Expr(column+" BETWEEN ? AND ?", from, to)
+	})
+}
+
+func (t *TableQueryBuilder) WhereLike(column string, pattern string) QueryBuilder {
+	return t.addColumnWhere(column, func(column string) sq.Sqlizer {
+		return sq.Expr(column+" LIKE ?", pattern)
+	})
+}
+
+func (t *TableQueryBuilder) WhereColumn(first, operator, second string) QueryBuilder {
+	op, err := comparisonOperator(operator)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	other, err := quoteIdentifier(second)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	return t.addColumnWhere(first, func(column string) sq.Sqlizer {
+		return sq.Expr(column + " " + op + " " + other)
+	})
+}
+
+func (t *TableQueryBuilder) addWhere(boolean string, query any, args []any, negate bool) QueryBuilder {
+	condition, err := t.condition(query, args)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	if negate {
+		condition = notCondition{condition: condition}
+	}
+	t.wheres = append(t.wheres, whereClause{boolean: boolean, condition: condition})
+	return t
+}
+
+func (t *TableQueryBuilder) addColumnWhere(column string, build func(column string) sq.Sqlizer) QueryBuilder {
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	t.wheres = append(t.wheres, whereClause{boolean: "AND", condition: build(quoted)})
+	return t
+}
+
+func (t *TableQueryBuilder) condition(query any, args []any) (sq.Sqlizer, error) {
+	fn, ok := query.(func(QueryBuilder))
+	if !ok {
+		return toSqlizer(query, args)
+	}
+
+	group := t.newQuery(t.table)
+	fn(group)
+	if group.err != nil {
+		return nil, group.err
+	}
+	return whereGroup(group.wheres), nil
+}
+
+func (t *TableQueryBuilder) Join(query string, args ...any) QueryBuilder {
+	t.joins = append(t.joins, sq.Expr(query, args...))
+	return t
+}
+
+func (t *TableQueryBuilder) OrderBy(column string, direction ...string) QueryBuilder {
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	dir, err := sortDirection(direction...)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	t.orderBys = append(t.orderBys, quoted+" "+dir)
+	return t
+}
+
+func (t *TableQueryBuilder) GroupBy(columns ...string) QueryBuilder {
+	for _, column := range columns {
+		quoted, err := quoteIdentifier(column)
+		if err != nil {
+			t.err = err
+			return t
+		}
+		t.groupBys = append(t.groupBys, quoted)
+	}
+	return t
+}
+
+func (t *TableQueryBuilder) Having(query any, args ...any) QueryBuilder {
+	condition, err := toSqlizer(query, args)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	t.havings = append(t.havings, condition)
+	return t
+}
+
+func (t *TableQueryBuilder) Limit(limit int) QueryBuilder {
+	t.limit = limit
+	return t
+}
+
+func (t *TableQueryBuilder) Offset(offset int) QueryBuilder {
+	t.offset = offset
+	return t
+}
+
+func (t *TableQueryBuilder) Preload(query string, args ...any) QueryBuilder {
+	return t.With(query)
+}
+
+func (t *TableQueryBuilder) Joins(query string, args ...any) QueryBuilder {
+	return t.Join(query, args...)
+}
+
+func (t *TableQueryBuilder) With(relations ...string) QueryBuilder {
+	t.eager = append(t.eager, relations...)
+	return t
+}
+
+func (t *TableQueryBuilder) WhereHas(relation string, fn func(QueryBuilder)) QueryBuilder {
+	exists, err := t.existsClause(t.table, relation, fn)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	t.wheres = append(t.wheres, whereClause{boolean: "AND", condition: exists})
+	return t
+}
+
+func (t *TableQueryBuilder) FromSub(builder QueryBuilder, alias string) QueryBuilder {
+	quoted, err := quoteIdentifier(alias)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	sub, err := toSubquery(builder)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	t.from = sq.Alias(sub, quoted)
+	t.table = alias
+	return t
+}
+
+func (t *TableQueryBuilder) WithCTE(name string, builder QueryBuilder) QueryBuilder {
+	return t.addCommonTable(name, builder, false)
+}
+
+func (t *TableQueryBuilder) WithRecursive(name string, builder QueryBuilder) QueryBuilder {
+	return t.addCommonTable(name, builder, true)
+}
+
+func (t *TableQueryBuilder) Union(builder QueryBuilder) QueryBuilder {
+	return t.addUnion(builder, false)
+}
+
+func (t *TableQueryBuilder) UnionAll(builder QueryBuilder) QueryBuilder {
+	return t.addUnion(builder, true)
+}
+
+func (t *TableQueryBuilder) addCommonTable(name string, builder QueryBuilder, recursive bool) QueryBuilder {
+	quoted, err := quoteIdentifier(name)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	sub, err := toSubquery(builder)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	t.ctes = append(t.ctes, commonTable{name: quoted, recursive: recursive, query: sub})
+	return t
+}
+
+func (t *TableQueryBuilder) addUnion(builder QueryBuilder, all bool) QueryBuilder {
+	sub, err := toSubquery(builder)
+	if err != nil {
+		t.err = err
+		return t
+	}
+
+	t.unions = append(t.unions, union{all: all, query: sub})
+	return t
+}
+
+func (t *TableQueryBuilder) Find(dest any) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Pointer || destValue.IsNil() {
+		return fmt.Errorf("gokit: Find expects a non-nil pointer, got %T", dest)
+	}
+
+	target := destValue.Elem()
+	if target.Kind() != reflect.Slice {
+		return t.first(target, false)
+	}
+
+	records, _, err := t.fetch(target.Type().Elem())
+	if err != nil {
+		return err
+	}
+	if err := t.eagerLoad(records); err != nil {
+		return err
+	}
+
+	target.Set(records)
+	return nil
+}
+
+func (t *TableQueryBuilder) First(dest any) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Pointer || destValue.IsNil() {
+		return fmt.Errorf("gokit: First expects a non-nil pointer, got %T", dest)
+	}
+	return t.first(destValue.Elem(), true)
+}
+
+func (t *TableQueryBuilder) first(target reflect.Value, required bool) error {
+	t.limit = 1
+	records, _, err := t.fetch(target.Type())
+	if err != nil {
+		return err
+	}
+	if records.Len() == 0 {
+		if required {
+			return ErrRecordNotFound
+		}
+		return nil
+	}
+	if err := t.eagerLoad(records); err != nil {
+		return err
+	}
+
+	target.Set(records.Index(0))
+	return nil
+}
+
+func (t *TableQueryBuilder) fetch(elemType reflect.Type) (reflect.Value, []map[string]any, error) {
+	query, args, err := t.ToSQL()
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+
+	rows, err := t.runner.QueryContext(t.ctx, query, args...)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	return scanRows(rows, elemType)
+}
+
+func (t *TableQueryBuilder) eagerLoad(records reflect.Value) error {
+	if len(t.eager) == 0 || records.Len() == 0 {
+		return nil
+	}
+	return t.loadRelations(records, t.table, buildEagerTree(t.eager))
+}
+
+func (t *TableQueryBuilder) Create(value any) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	records := reflect.ValueOf(value)
+	for records.Kind() == reflect.Pointer {
+		records = records.Elem()
+	}
+	if records.Kind() != reflect.Slice {
+		records = reflect.Append(reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(value)), 0, 1), reflect.ValueOf(value))
+	}
+	if records.Len() == 0 {
+		return nil
+	}
+
+	insert := sq.Insert(t.table)
+	var columns []string
+	var returning []reflect.Value
+
+	for i := 0; i < records.Len(); i++ {
+		record := records.Index(i)
+		for record.Kind() == reflect.Pointer || record.Kind() == reflect.Interface {
+			record = record.Elem()
+		}
+
+		var values []any
+		switch record.Kind() {
+		case reflect.Map:
+			data, ok := record.Interface().(map[string]any)
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrUnsupportedArgument, record.Type())
+			}
+			if columns == nil {
+				columns = sortedKeys(data)
+			}
+			for _, column := range columns {
+				values = append(values, data[column])
+			}
+		case reflect.Struct:
+			touchTimestamps(record, "created_at", "updated_at")
+			cols, vals := structValues(record, false)
+			if id, ok := getStructInfo(record.Type()).columns["id"]; ok && record.FieldByIndex(id).IsZero() {
+				cols, vals = withoutColumn(cols, vals, "id")
+				if record.CanAddr() {
+					returning = append(returning, record.FieldByIndex(id))
+				}
+			}
+			if columns == nil {
+				columns = cols
+			}
+			values = vals
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedArgument, record.Type())
+		}
+		insert = insert.Values(values...)
+	}
+	insert = insert.Columns(columns...)
+
+	if len(returning) != records.Len() {
+		return t.exec(insert)
+	}
+
+	query, args, err := t.render(insert.Suffix("RETURNING id"))
+	if err != nil {
+		return err
+	}
+
+	rows, err := t.runner.QueryContext(t.ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for i := 0; rows.Next() && i < len(returning); i++ {
+		var id any
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		if err := assignValue(returning[i], id); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (t *TableQueryBuilder) Update(values any) error {
+	if t.err != nil {
+		return t.err
+	}
+	if len(t.wheres) == 0 {
+		return ErrMissingWhereClause
+	}
+
+	data := make(map[string]any)
+	record := reflect.ValueOf(values)
+	for record.Kind() == reflect.Pointer {
+		record = record.Elem()
+	}
+
+	switch record.Kind() {
+	case reflect.Map:
+		m, ok := record.Interface().(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedArgument, record.Type())
+		}
+		data = m
+	case reflect.Struct:
+		touchTimestamps(record, "updated_at")
+		columns, vals := structValues(record, true)
+		for i, column := range columns {
+			data[column] = vals[i]
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedArgument, record.Type())
+	}
+
+	if err := t.prepare(); err != nil {
+		return err
+	}
+	return t.exec(sq.Update(t.table).SetMap(data).Where(whereGroup(t.wheres)))
+}
+
+func (t *TableQueryBuilder) Delete() error {
+	if t.err != nil {
+		return t.err
+	}
+	if len(t.wheres) == 0 {
+		return ErrMissingWhereClause
+	}
+
+	if err := t.prepare(); err != nil {
+		return err
+	}
+	return t.exec(sq.Delete(t.table).Where(whereGroup(t.wheres)))
+}
+
+func (t *TableQueryBuilder) Count() (int64, error) {
+	var count int64
+	err := t.aggregate("COUNT(%s)", "*", &count)
+	return count, err
+}
+
+func (t *TableQueryBuilder) CountDistinct(column string) (int64, error) {
+	var count int64
+	err := t.aggregate("COUNT(DISTINCT %s)", column, &count)
+	return count, err
+}
+
+func (t *TableQueryBuilder) Sum(column string) (float64, error) {
+	return t.aggregateFloat("SUM", column)
+}
+
+func (t *TableQueryBuilder) Avg(column string) (float64, error) {
+	return t.aggregateFloat("AVG", column)
+}
+
+func (t *TableQueryBuilder) Min(column string) (float64, error) {
+	return t.aggregateFloat("MIN", column)
+}
+
+func (t *TableQueryBuilder) Max(column string) (float64, error) {
+	return t.aggregateFloat("MAX", column)
+}
+
+func (t *TableQueryBuilder) Exists() (bool, error) {
+	if err := t.prepare(); err != nil {
+		return false, err
+	}
+
+	inner := t.unordered()
+	inner.columns = []string{"1"}
+	query := sq.Expr("SELECT EXISTS (?)", withUnions(t.unions, inner.selectBuilder()))
+
+	var exists bool
+	err := t.queryRow(withCommonTables(t.ctes, query), &exists)
+	return exists, err
+}
+
+func (t *TableQueryBuilder) Pluck(column string, dest any) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Pointer || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("gokit: Pluck expects a pointer to a slice, got %T", dest)
+	}
+
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		return err
+	}
+
+	query := *t
+	query.columns = []string{quoted}
+	values, _, err := query.fetch(destValue.Elem().Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	destValue.Elem().Set(values)
+	return nil
+}
+
+func (t *TableQueryBuilder) Value(column string, dest any) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Pointer || destValue.IsNil() {
+		return fmt.Errorf("gokit: Value expects a non-nil pointer, got %T", dest)
+	}
+
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		return err
+	}
+
+	query := *t
+	query.columns = []string{quoted}
+	query.limit = 1
+	values, _, err := query.fetch(destValue.Elem().Type())
+	if err != nil {
+		return err
+	}
+	if values.Len() == 0 {
+		return ErrRecordNotFound
+	}
+
+	destValue.Elem().Set(values.Index(0))
+	return nil
+}
+
+func (t *TableQueryBuilder) aggregateFloat(function, column string) (float64, error) {
+	var result sql.NullFloat64
+	err := t.aggregate(function+"(%s)", column, &result)
+	return result.Float64, err
+}
+
+// aggregate applies format to column over the current query. column must be
+// an identifier; expressions belong in Select under an alias. Grouped and
+// unioned queries are wrapped in a subquery and aggregated over their rows,
+// so column must name one of the columns or aliases they select, e.g.
+// Select("customer_id", "SUM(amount) AS total").GroupBy("customer_id").Avg("total").
+// Otherwise ErrAggregateNotSelected is returned rather than invalid SQL.
+func (t *TableQueryBuilder) aggregate(format, column string, dest any) error {
+	if err := t.prepare(); err != nil {
+		return err
+	}
+
+	inner := t.unordered()
+	var query sq.Sqlizer
+	if len(t.groupBys) > 0 || len(t.unions) > 0 {
+		if len(inner.columns) == 0 && len(t.groupBys) > 0 {
+			inner.columns = t.groupBys
+		}
+		reference, err := aggregatedColumn(inner.columns, column)
+		if err != nil {
+			return err
+		}
+		query = sq.Expr("SELECT "+fmt.Sprintf(format, reference)+" FROM (?) AS aggregated", withUnions(t.unions, inner.selectBuilder()))
+	} else {
+		reference, err := aggregateColumn(column)
+		if err != nil {
+			return err
+		}
+		inner.columns = []string{fmt.Sprintf(format, reference)}
+		query = inner.selectBuilder()
+	}
+
+	return t.queryRow(withCommonTables(t.ctes, query), dest)
+}
+
+func (t *TableQueryBuilder) queryRow(statement sq.Sqlizer, dest ...any) error {
+	query, args, err := t.render(statement)
+	if err != nil {
+		return err
+	}
+	return t.runner.QueryRowContext(t.ctx, query, args...).Scan(dest...)
+}
+
+func (t *TableQueryBuilder) exec(statement sq.Sqlizer) error {
+	query, args, err := t.render(statement)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.runner.ExecContext(t.ctx, query, args...)
+	return err
+}
+
+func (t *TableQueryBuilder) render(statement sq.Sqlizer) (string, []any, error) {
+	query, args, err := statement.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	query, err = t.placeholder.ReplacePlaceholders(query)
+	return query, args, err
+}
+
+func (t *TableQueryBuilder) unordered() TableQueryBuilder {
+	inner := *t
+	inner.orderBys = nil
+	inner.limit = -1
+	inner.offset = -1
+	return inner
+}
+
+func (t *TableQueryBuilder) Begin() (QueryBuilder, error) {
+	db, ok := t.runner.(*sql.DB)
+	if !ok {
+		return nil, fmt.Errorf("gokit: cannot begin a transaction on %T", t.runner)
+	}
+
+	tx, err := db.BeginTx(t.ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := t.newQuery(t.table)
+	query.runner = tx
+	return query, nil
+}
+
+func (t *TableQueryBuilder) Commit() error {
+	tx, ok := t.runner.(*sql.Tx)
+	if !ok {
+		return ErrNotInTransaction
+	}
+	return tx.Commit()
+}
+
+func (t *TableQueryBuilder) Rollback() error {
+	tx, ok := t.runner.(*sql.Tx)
+	if !ok {
+		return ErrNotInTransaction
+	}
+	return tx.Rollback()
+}
+
+func (t *TableQueryBuilder) ToSQL() (string, []any, error) {
+	statement, err := t.subquery()
+	if err != nil {
+		return "", nil, err
+	}
+	return t.render(statement)
+}
+
+func (t *TableQueryBuilder) subquery() (sq.Sqlizer, error) {
+	if err := t.prepare(); err != nil {
+		return nil, err
+	}
+	return withCommonTables(t.ctes, withUnions(t.unions, t.selectBuilder())), nil
+}
+
+func (t *TableQueryBuilder) selectBuilder() sq.SelectBuilder {
+	columns := t.columns
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+
+	query := sq.Select(columns...)
+	if t.from != nil {
+		// squirrel's From only takes a plain string, so a derived table is
+		// emitted as the leading join clause to keep its arguments in order.
+		query = query.JoinClause(sq.Expr("FROM ?", t.from))
+	} else {
+		query = query.From(t.table)
+	}
+	for _, join := range t.joins {
+		query = query.JoinClause(join)
+	}
+	if len(t.wheres) > 0 {
+		query = query.Where(whereGroup(t.wheres))
+	}
+	if len(t.groupBys) > 0 {
+		query = query.GroupBy(t.groupBys...)
+	}
+	if len(t.havings) > 0 {
+		query = query.Having(sq.And(t.havings))
+	}
+	if len(t.orderBys) > 0 {
+		query = query.OrderBy(t.orderBys...)
+	}
+	if t.limit >= 0 {
+		query = query.Limit(uint64(t.limit))
+	}
+	if t.offset >= 0 {
+		query = query.Offset(uint64(t.offset))
+	}
+	return query
+}
+
+func toSqlizer(query any, args []any) (sq.Sqlizer, error) {
+	switch q := query.(type) {
+	case string:
+		return sq.Expr(q, args...), nil
+	case sq.Sqlizer:
+		return q, nil
+	case map[string]any:
+		return sq.Eq(q), nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedArgument, query)
+}
+
+func touchTimestamps(record reflect.Value, columns ...string) {
+	if !record.CanAddr() {
+		return
+	}
+
+	info := getStructInfo(record.Type())
+	now := time.Now()
+	for _, column := range columns {
+		index, ok := info.columns[column]
+		if !ok {
+			continue
+		}
+		field := record.FieldByIndex(index)
+		if field.Type() == timeType && (field.IsZero() || column == "updated_at") {
+			field.Set(reflect.ValueOf(now))
+		}
+	}
+}
+
+func withoutColumn(columns []string, values []any, name string) ([]string, []any) {
+	for i, column := range columns {
+		if column == name {
+			return append(columns[:i:i], columns[i+1:]...), append(values[:i:i], values[i+1:]...)
+		}
+	}
+	return columns, values
+}
+
+func sortedKeys(data map[string]any) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
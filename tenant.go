@@ -0,0 +1,61 @@
+package gokit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var ErrTenantNotResolved = errors.New("gokit: tenant not resolved for request")
+
+type tenantKey struct{}
+
+type TenantResolver func(ctx Context) (any, error)
+
+func WithTenant(ctx context.Context, tenant any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func TenantFromContext(ctx context.Context) (any, bool) {
+	tenant := ctx.Value(tenantKey{})
+	return tenant, tenant != nil
+}
+
+func HeaderTenantResolver(header string) TenantResolver {
+	return func(ctx Context) (any, error) {
+		if tenant := ctx.Header(header); tenant != "" {
+			return tenant, nil
+		}
+		return nil, ErrTenantNotResolved
+	}
+}
+
+func TenantMiddleware(resolver TenantResolver) MiddlewareFunc {
+	return func(ctx Context, next Next) {
+		tenant, err := resolver(ctx)
+		if err != nil {
+			ctx.Error(NewHTTPError(http.StatusBadRequest, "tenant_not_resolved", "The request tenant could not be resolved.").Wrap(err))
+			return
+		}
+		ctx.SetRequest(ctx.Request().WithContext(WithTenant(ctx.Request().Context(), tenant)))
+		next()
+	}
+}
+
+// TenantScope restricts queries to the tenant carried by ctx. Queries without
+// a resolved tenant fail instead of silently reading across tenants.
+func TenantScope(column string) GlobalScope {
+	return func(ctx context.Context, query QueryBuilder) error {
+		tenant, ok := TenantFromContext(ctx)
+		if !ok {
+			return ErrTenantNotResolved
+		}
+
+		quoted, err := quoteIdentifier(column)
+		if err != nil {
+			return err
+		}
+		query.Where(quoted+" = ?", tenant)
+		return nil
+	}
+}
@@ -0,0 +1,219 @@
+package gokit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// writeCert generates a certificate signed by parent, or self-signed when
+// parent is nil, and writes the PEM pair into dir.
+func writeCert(t *testing.T, dir, name string, serial int64, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	if err := os.WriteFile(generated.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(generated.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return generated
+}
+
+func touch(t *testing.T, at time.Time, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		if err := os.Chtimes(file, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func leafSerial(t *testing.T, reloader *certReloader) int64 {
+	t.Helper()
+	reloader.lastCheck = time.Time{}
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint16
+	}{
+		{"", tls.VersionTLS12},
+		{"1.2", tls.VersionTLS12},
+		{"1.3", tls.VersionTLS13},
+		{"TLS1.3", tls.VersionTLS13},
+		{" tls1.0 ", tls.VersionTLS10},
+	}
+	for _, tt := range tests {
+		got, err := parseTLSVersion(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("parseTLSVersion(%q) = %x, %v, want %x", tt.input, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseTLSVersion("1.4"); !errors.Is(err, ErrInvalidTLSVersion) {
+		t.Errorf("parseTLSVersion(%q) error = %v, want ErrInvalidTLSVersion", "1.4", err)
+	}
+}
+
+func TestCertReloaderPicksUpRotation(t *testing.T) {
+	dir := t.TempDir()
+	first := writeCert(t, dir, "server", 1, nil, false)
+	touch(t, time.Now().Add(-time.Minute), first.certFile, first.keyFile)
+
+	reloader, err := newCertReloader(first.certFile, first.keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serial := leafSerial(t, reloader); serial != 1 {
+		t.Fatalf("serial = %d, want 1", serial)
+	}
+
+	writeCert(t, dir, "server", 2, nil, false)
+	touch(t, time.Now(), first.certFile, first.keyFile)
+	if serial := leafSerial(t, reloader); serial != 2 {
+		t.Fatalf("serial after rotation = %d, want 2", serial)
+	}
+}
+
+func TestCertReloaderKeepsCertificateOnBadRotation(t *testing.T) {
+	dir := t.TempDir()
+	first := writeCert(t, dir, "server", 1, nil, false)
+	touch(t, time.Now().Add(-time.Minute), first.certFile, first.keyFile)
+
+	reloader, err := newCertReloader(first.certFile, first.keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(first.keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, time.Now(), first.certFile, first.keyFile)
+	if serial := leafSerial(t, reloader); serial != 1 {
+		t.Fatalf("serial after bad rotation = %d, want 1", serial)
+	}
+}
+
+func TestTLSConfigRequiresClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := writeCert(t, dir, "ca", 1, nil, true)
+	server := writeCert(t, dir, "server", 2, ca, false)
+	client := writeCert(t, dir, "client", 3, ca, false)
+
+	r := NewRouter().(*router)
+	r.serverConfig.TLSClientCA = ca.certFile
+	r.GET("/", func(ctx Context) {
+		ctx.String(http.StatusOK, ctx.Request().TLS.PeerCertificates[0].Subject.CommonName)
+	})
+
+	config, err := r.tlsConfig(server.certFile, server.keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Fatalf("ClientAuth = %v, want RequireAndVerifyClientCert", config.ClientAuth)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: r}
+	go srv.Serve(listener)
+	t.Cleanup(func() { srv.Close() })
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	url := "https://" + listener.Addr().String() + "/"
+
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	if resp, err := anonymous.Get(url); err == nil {
+		resp.Body.Close()
+		t.Fatal("request without a client certificate succeeded")
+	}
+
+	pair, err := tls.LoadX509KeyPair(client.certFile, client.keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticated := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{pair},
+	}}}
+	resp, err := authenticated.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,448 @@
+package gokit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const DefaultLocale = "en"
+
+var (
+	ErrValidateTarget       = errors.New("gokit: validation target must be a struct or a pointer to one")
+	ErrUnknownRule          = errors.New("gokit: unknown validation rule")
+	ErrInvalidRuleParam     = errors.New("gokit: invalid validation rule parameter")
+	ErrValidationDatabase   = errors.New("gokit: database validation rules require a database")
+	numericPattern          = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)$`)
+	defaultValidationLocale = map[string]map[string]string{
+		"en": {
+			"default":     "The {field} field is invalid.",
+			"required":    "The {field} field is required.",
+			"email":       "The {field} field must be a valid email address.",
+			"url":         "The {field} field must be a valid URL.",
+			"uuid":        "The {field} field must be a valid UUID.",
+			"numeric":     "The {field} field must be a number.",
+			"min.string":  "The {field} field must be at least {param} characters.",
+			"min.numeric": "The {field} field must be at least {param}.",
+			"min.array":   "The {field} field must have at least {param} items.",
+			"max.string":  "The {field} field must not be greater than {param} characters.",
+			"max.numeric": "The {field} field must not be greater than {param}.",
+			"max.array":   "The {field} field must not have more than {param} items.",
+			"len.string":  "The {field} field must be {param} characters.",
+			"len.numeric": "The {field} field must be {param}.",
+			"len.array":   "The {field} field must contain {param} items.",
+			"oneof":       "The {field} field must be one of: {param}.",
+			"unique":      "The {field} has already been taken.",
+			"exists":      "The selected {field} is invalid.",
+		},
+		"pt": {
+			"default":     "O campo {field} é inválido.",
+			"required":    "O campo {field} é obrigatório.",
+			"email":       "O campo {field} deve ser um endereço de e-mail válido.",
+			"url":         "O campo {field} deve ser uma URL válida.",
+			"uuid":        "O campo {field} deve ser um UUID válido.",
+			"numeric":     "O campo {field} deve ser um número.",
+			"min.string":  "O campo {field} deve ter pelo menos {param} caracteres.",
+			"min.numeric": "O campo {field} deve ser pelo menos {param}.",
+			"min.array":   "O campo {field} deve ter pelo menos {param} itens.",
+			"max.string":  "O campo {field} não pode ter mais de {param} caracteres.",
+			"max.numeric": "O campo {field} não pode ser maior que {param}.",
+			"max.array":   "O campo {field} não pode ter mais de {param} itens.",
+			"len.string":  "O campo {field} deve ter {param} caracteres.",
+			"len.numeric": "O campo {field} deve ser {param}.",
+			"len.array":   "O campo {field} deve conter {param} itens.",
+			"oneof":       "O campo {field} deve ser um de: {param}.",
+			"unique":      "O valor do campo {field} já está em uso.",
+			"exists":      "O valor selecionado para {field} é inválido.",
+		},
+	}
+)
+
+// ValidationRule reports whether value satisfies the rule. param is the text
+// after "=" in the tag. Returning an error aborts validation.
+type ValidationRule func(ctx context.Context, value any, param string) (bool, error)
+
+type localeKey struct{}
+
+// WithLocale selects the language used for validation messages.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+func localeFrom(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+func (r *router) SetValidator(validator Validator) {
+	r.root.validator = validator
+}
+
+// BindAndValidate binds dst and validates it in the language of the
+// request's Accept-Language header. Validation failures render as a 422.
+func (c *Ctx) BindAndValidate(dst any) error {
+	if err := c.Bind(dst); err != nil {
+		return err
+	}
+	validator := c.validator
+	if validator == nil {
+		validator = NewValidator()
+	}
+	return validator.Validate(WithLocale(c.Context(), requestLocale(c.request)), dst)
+}
+
+// requestLocale returns the first language in Accept-Language.
+func requestLocale(req *http.Request) string {
+	first, _, _ := strings.Cut(req.Header.Get("Accept-Language"), ",")
+	tag, _, _ := strings.Cut(first, ";")
+	if tag = strings.TrimSpace(tag); tag == "" || tag == "*" {
+		return DefaultLocale
+	}
+	return tag
+}
+
+type validator struct {
+	rules    map[string]ValidationRule
+	messages map[string]map[string]string
+	db       Database
+	mu       sync.RWMutex
+}
+
+func NewValidator() Validator {
+	v := &validator{
+		rules:    make(map[string]ValidationRule),
+		messages: make(map[string]map[string]string),
+	}
+	v.rules["email"] = ruleEmail
+	v.rules["url"] = ruleURL
+	v.rules["uuid"] = ruleUUID
+	v.rules["numeric"] = ruleNumeric
+	v.rules["min"] = ruleCompare(func(size, limit float64) bool { return size >= limit })
+	v.rules["max"] = ruleCompare(func(size, limit float64) bool { return size <= limit })
+	v.rules["len"] = ruleCompare(func(size, limit float64) bool { return size == limit })
+	v.rules["oneof"] = ruleOneOf
+	v.rules["unique"] = v.ruleUnique
+	v.rules["exists"] = v.ruleExists
+	for locale, messages := range defaultValidationLocale {
+		v.RegisterMessages(locale, messages)
+	}
+	return v
+}
+
+func (v *validator) RegisterRule(name string, rule ValidationRule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = rule
+}
+
+// RegisterMessages adds or overrides message templates for locale. Templates
+// may use {field} and {param}; min, max and len are keyed by the kind of
+// value, e.g. "min.string".
+func (v *validator) RegisterMessages(locale string, messages map[string]string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.messages[locale] == nil {
+		v.messages[locale] = make(map[string]string)
+	}
+	for key, message := range messages {
+		v.messages[locale][key] = message
+	}
+}
+
+func (v *validator) SetDatabase(db Database) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.db = db
+}
+
+// Validate checks the validate tags of target and of any nested structs and
+// slices of structs. Failures are returned as ValidationErrors keyed by the
+// json name of each field, e.g. "items.0.name".
+func (v *validator) Validate(ctx context.Context, target any) error {
+	value := reflect.ValueOf(target)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return ErrValidateTarget
+	}
+
+	errs := make(ValidationErrors)
+	if err := v.validateStruct(ctx, value, "", errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *validator) validateStruct(ctx context.Context, target reflect.Value, prefix string, errs ValidationErrors) error {
+	var err error
+	walkFields(target, func(field reflect.Value, info reflect.StructField) {
+		if err != nil {
+			return
+		}
+		tag := info.Tag.Get("validate")
+		if tag == "-" {
+			return
+		}
+		name := formName(info)
+		if name == "" {
+			return
+		}
+		if err = v.validateField(ctx, field, name, prefix+name, tag, errs); err != nil {
+			return
+		}
+		err = v.validateNested(ctx, field, prefix+name, errs)
+	})
+	return err
+}
+
+// validateField stops at the first failing rule. Empty fields only fail
+// required; their other rules are skipped.
+func (v *validator) validateField(ctx context.Context, field reflect.Value, name, key, tag string, errs ValidationErrors) error {
+	if tag == "" {
+		return nil
+	}
+	rules := strings.Split(tag, ",")
+	value := indirect(field)
+	if !present(field) {
+		if hasRule(rules, "required") {
+			errs.Add(key, v.message(localeFrom(ctx), "required", name, ""))
+		}
+		return nil
+	}
+
+	for _, rule := range rules {
+		ruleName, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if ruleName == "" || ruleName == "required" {
+			continue
+		}
+
+		v.mu.RLock()
+		fn, ok := v.rules[ruleName]
+		v.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: %q on field %s", ErrUnknownRule, ruleName, key)
+		}
+
+		var input any
+		if value.IsValid() {
+			input = value.Interface()
+		}
+		passed, err := fn(ctx, input, param)
+		if err != nil {
+			return fmt.Errorf("gokit: validation rule %s on field %s: %w", ruleName, key, err)
+		}
+		if !passed {
+			errs.Add(key, v.message(localeFrom(ctx), messageKey(ruleName, value), name, param))
+			return nil
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateNested(ctx context.Context, field reflect.Value, key string, errs ValidationErrors) error {
+	value := indirect(field)
+	if !value.IsValid() {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == timeType {
+			return nil
+		}
+		return v.validateStruct(ctx, value, key+".", errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := indirect(value.Index(i))
+			if item.Kind() == reflect.Struct && item.Type() != timeType {
+				if err := v.validateStruct(ctx, item, key+"."+strconv.Itoa(i)+".", errs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// message resolves the template for key in locale, falling back to the base
+// language and then to DefaultLocale.
+func (v *validator) message(locale, key, field, param string) string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	candidates := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, DefaultLocale)
+
+	template := ""
+	for _, lookup := range []string{key, strings.Split(key, ".")[0], "default"} {
+		for _, candidate := range candidates {
+			if message, ok := v.messages[candidate][lookup]; ok {
+				template = message
+				break
+			}
+		}
+		if template != "" {
+			break
+		}
+	}
+
+	param = strings.Join(strings.Fields(param), ", ")
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(template)
+}
+
+func messageKey(rule string, value reflect.Value) string {
+	if rule != "min" && rule != "max" && rule != "len" {
+		return rule
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return rule + ".string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rule + ".array"
+	}
+	return rule + ".numeric"
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if ruleName, _, _ := strings.Cut(strings.TrimSpace(rule), "="); ruleName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// present treats blank strings and empty collections as missing. A non-nil
+// pointer counts as provided even when it points to a zero number or false.
+func present(field reflect.Value) bool {
+	pointer := field.Kind() == reflect.Pointer
+	value := indirect(field)
+	if !value.IsValid() {
+		return false
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) != ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() > 0
+	}
+	return pointer || !value.IsZero()
+}
+
+func ruleEmail(_ context.Context, value any, _ string) (bool, error) {
+	email := fmt.Sprint(value)
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email, nil
+}
+
+func ruleURL(_ context.Context, value any, _ string) (bool, error) {
+	parsed, err := url.ParseRequestURI(fmt.Sprint(value))
+	return err == nil && parsed.Scheme != "" && parsed.Host != "", nil
+}
+
+func ruleUUID(_ context.Context, value any, _ string) (bool, error) {
+	return uuidPattern.MatchString(fmt.Sprint(value)), nil
+}
+
+func ruleNumeric(_ context.Context, value any, _ string) (bool, error) {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true, nil
+	}
+	return numericPattern.MatchString(fmt.Sprint(value)), nil
+}
+
+// ruleCompare measures strings by characters, collections by length and
+// numbers by value.
+func ruleCompare(compare func(size, limit float64) bool) ValidationRule {
+	return func(_ context.Context, value any, param string) (bool, error) {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, fmt.Errorf("%w: %q is not a number", ErrInvalidRuleParam, param)
+		}
+
+		rv := reflect.ValueOf(value)
+		var size float64
+		switch rv.Kind() {
+		case reflect.String:
+			size = float64(utf8.RuneCountInString(rv.String()))
+		case reflect.Slice, reflect.Array, reflect.Map:
+			size = float64(rv.Len())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			size = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			size = float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			size = rv.Float()
+		default:
+			return false, nil
+		}
+		return compare(size, limit), nil
+	}
+}
+
+func ruleOneOf(_ context.Context, value any, param string) (bool, error) {
+	return slices.Contains(strings.Fields(param), fmt.Sprint(value)), nil
+}
+
+func (v *validator) ruleUnique(ctx context.Context, value any, param string) (bool, error) {
+	exists, err := v.recordExists(ctx, value, param)
+	return !exists, err
+}
+
+func (v *validator) ruleExists(ctx context.Context, value any, param string) (bool, error) {
+	return v.recordExists(ctx, value, param)
+}
+
+// recordExists looks value up in the table.column named by param.
+func (v *validator) recordExists(ctx context.Context, value any, param string) (bool, error) {
+	v.mu.RLock()
+	db := v.db
+	v.mu.RUnlock()
+	if db == nil {
+		return false, ErrValidationDatabase
+	}
+
+	table, column, found := strings.Cut(param, ".")
+	if !found || table == "" || column == "" {
+		return false, fmt.Errorf("%w: %q must be table.column", ErrInvalidRuleParam, param)
+	}
+	if _, err := quoteIdentifier(table); err != nil {
+		return false, err
+	}
+	quoted, err := quoteIdentifier(column)
+	if err != nil {
+		return false, err
+	}
+	return db.Table(table).WithContext(ctx).Where(quoted+" = ?", value).Exists()
+}
@@ -0,0 +1,128 @@
+package gokit
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionConfig selects how requests pick an API version. With Prefix, each
+// Version group is mounted under /<version>. Otherwise groups share paths and
+// the version comes from the Header, or from an Accept media type such as
+// application/vnd.<Vendor>.v2+json, falling back to Default.
+type VersionConfig struct {
+	Prefix  bool
+	Header  string
+	Vendor  string
+	Default string
+}
+
+func DefaultVersionConfig() VersionConfig {
+	return VersionConfig{
+		Prefix: true,
+		Header: "X-API-Version",
+	}
+}
+
+type deprecation struct {
+	deprecatedAt time.Time
+	sunset       time.Time
+}
+
+// SetVersioning must be called before Version groups are registered.
+func (r *router) SetVersioning(config VersionConfig) {
+	r.root.versioning = config
+	r.root.vendorPattern = nil
+	if config.Vendor != "" {
+		r.root.vendorPattern = regexp.MustCompile(`application/vnd\.` + regexp.QuoteMeta(config.Vendor) + `\.([A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)*?)(?:\+[a-z]+)?(?:\s*[;,]|$)`)
+	}
+}
+
+func (r *router) Version(version string, fn func(Router), middleware ...MiddlewareFunc) {
+	prefix := r.prefix
+	if r.root.versioning.Prefix {
+		prefix += "/" + version
+	}
+
+	groupRouter := r.subrouter(prefix, r.host, version, middleware)
+	fn(groupRouter)
+	r.routes = append(r.routes, groupRouter.routes...)
+}
+
+// DeprecateVersion makes responses from version carry Deprecation and, when
+// sunset is set, Sunset headers.
+func (r *router) DeprecateVersion(version string, deprecatedAt, sunset time.Time) {
+	r.root.deprecations[version] = deprecation{deprecatedAt: deprecatedAt, sunset: sunset}
+}
+
+func (r *router) markDeprecated(w http.ResponseWriter, version string) {
+	deprecated, exists := r.deprecations[version]
+	if !exists {
+		return
+	}
+
+	if deprecated.deprecatedAt.IsZero() {
+		w.Header().Set("Deprecation", "true")
+	} else {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(deprecated.deprecatedAt.Unix(), 10))
+	}
+	if !deprecated.sunset.IsZero() {
+		w.Header().Set("Sunset", deprecated.sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// requestedVersion reads the version from the configured header, then the
+// Accept vendor media type, then the default.
+func (r *router) requestedVersion(req *http.Request) string {
+	config := r.versioning
+	if config.Header != "" {
+		if version := strings.TrimSpace(req.Header.Get(config.Header)); version != "" {
+			return version
+		}
+	}
+
+	if r.vendorPattern != nil {
+		if match := r.vendorPattern.FindStringSubmatch(req.Header.Get("Accept")); match != nil {
+			return match[1]
+		}
+	}
+	return config.Default
+}
+
+// defaultVersionRequest rewrites an unversioned path to the default version
+// when versions are mounted by prefix, or returns nil if nothing matches there.
+func (r *router) defaultVersionRequest(req *http.Request) *http.Request {
+	config := r.versioning
+	if !config.Prefix || config.Default == "" {
+		return nil
+	}
+
+	fallback := req.Clone(req.Context())
+	fallback.URL.Path = "/" + config.Default + req.URL.Path
+	fallback.URL.RawPath = ""
+	if _, pattern := r.mux.Handler(fallback); pattern == "" {
+		return nil
+	}
+	return fallback
+}
+
+func (route *Route) acceptsVersion(req *http.Request) bool {
+	root := route.group.root
+	if route.version == "" || root.versioning.Prefix {
+		return true
+	}
+	return root.requestedVersion(req) == route.version
+}
+
+func (route *Route) servedVersion(req *http.Request) string {
+	if route.version != "" {
+		return route.version
+	}
+	return route.group.root.requestedVersion(req)
+}
+
+func (c *Ctx) Version() string {
+	return c.version
+}